@@ -0,0 +1,244 @@
+// Package errors provides an error type carrying key/value attributes, a captured stack,
+// a classification and an optional HTTP status, so loggers, spans and Sentry can report
+// errors with their context instead of a flat message.
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+)
+
+// Kind classifies the origin of an error
+type Kind int
+
+const (
+	// KindInternal is a bug or unexpected failure inside the service (default)
+	KindInternal Kind = iota
+	// KindUser is caused by invalid input or a request the caller should not retry as is
+	KindUser
+	// KindDependency is a failure of a downstream dependency (database, remote API, ...)
+	KindDependency
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindUser:
+		return "user"
+	case KindDependency:
+		return "dependency"
+	default:
+		return "internal"
+	}
+}
+
+// maxStackDepth is the maximum number of frames captured for an error
+const maxStackDepth = 32
+
+// Error is an error with attributes, a stack trace, a Kind and an optional HTTP status
+type Error struct {
+	msg     string
+	cause   error
+	kind    Kind
+	status  int
+	keyvals []interface{}
+	stack   []uintptr
+}
+
+// New creates an Error with the given message and key/value attributes, capturing the caller's stack
+func New(msg string, keyvals ...interface{}) *Error {
+	return newError(msg, nil, keyvals)
+}
+
+// Wrap creates an Error wrapping err, capturing the caller's stack.
+// Kind and status are inherited from err if it already carries an Error.
+func Wrap(err error, msg string, keyvals ...interface{}) *Error {
+	e := newError(msg, err, keyvals)
+	var inner *Error
+	if stderrors.As(err, &inner) {
+		e.kind = inner.kind
+		e.status = inner.status
+	}
+	return e
+}
+
+func newError(msg string, cause error, keyvals []interface{}) *Error {
+	var pcs [maxStackDepth]uintptr
+	// skip runtime.Callers, newError and the exported constructor
+	n := runtime.Callers(3, pcs[:])
+	return &Error{
+		msg:     msg,
+		cause:   cause,
+		keyvals: keyvals,
+		stack:   pcs[:n],
+	}
+}
+
+// WithKind sets the classification of the error and returns it
+func (e *Error) WithKind(kind Kind) *Error {
+	e.kind = kind
+	return e
+}
+
+// WithStatus sets the HTTP status that should be returned for the error and returns it
+func (e *Error) WithStatus(status int) *Error {
+	e.status = status
+	return e
+}
+
+// With appends key/value attributes to the error and returns it
+func (e *Error) With(keyvals ...interface{}) *Error {
+	e.keyvals = append(e.keyvals, keyvals...)
+	return e
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	if e.cause == nil {
+		return e.msg
+	}
+	if e.msg == "" {
+		return e.cause.Error()
+	}
+	return e.msg + ": " + e.cause.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Kind returns the classification of the error
+func (e *Error) Kind() Kind {
+	return e.kind
+}
+
+// Status returns the HTTP status set on the error, 0 if none
+func (e *Error) Status() int {
+	return e.status
+}
+
+// Attrs returns the key/value attributes attached to this error only
+func (e *Error) Attrs() []interface{} {
+	return e.keyvals
+}
+
+// StackTrace returns the program counters captured when the error was created.
+// The method name and shape let Sentry extract the stack like it does for pkg/errors.
+func (e *Error) StackTrace() []uintptr {
+	return e.stack
+}
+
+// Stack returns the captured stack formatted like a Go panic trace
+func (e *Error) Stack() string {
+	var sb strings.Builder
+	frames := runtime.CallersFrames(e.stack)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&sb, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return sb.String()
+}
+
+// As returns the outermost Error in err's chain
+func As(err error) (*Error, bool) {
+	var e *Error
+	ok := stderrors.As(err, &e)
+	return e, ok
+}
+
+// Attrs returns the attributes of every Error in err's chain, outermost first
+func Attrs(err error) []interface{} {
+	var keyvals []interface{}
+	for err != nil {
+		if e, ok := err.(*Error); ok {
+			keyvals = append(keyvals, e.keyvals...)
+		}
+		err = stderrors.Unwrap(err)
+	}
+	return keyvals
+}
+
+// Stack returns the formatted stack of the innermost Error in err's chain, empty if there is none
+func Stack(err error) string {
+	var stack string
+	for err != nil {
+		if e, ok := err.(*Error); ok {
+			stack = e.Stack()
+		}
+		err = stderrors.Unwrap(err)
+	}
+	return stack
+}
+
+// KindOf returns the Kind of err, KindInternal if it carries no Error
+func KindOf(err error) Kind {
+	if e, ok := As(err); ok {
+		return e.kind
+	}
+	return KindInternal
+}
+
+// HTTPStatus returns the HTTP status for err: the explicit status if one was set,
+// otherwise 400 for user errors, 502 for dependency errors and 500 for everything else
+func HTTPStatus(err error) int {
+	e, ok := As(err)
+	if !ok {
+		return http.StatusInternalServerError
+	}
+	if e.status != 0 {
+		return e.status
+	}
+	switch e.kind {
+	case KindUser:
+		return http.StatusBadRequest
+	case KindDependency:
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// PublicMessage returns a message safe to send to clients: the error message for
+// user errors and the generic status text otherwise
+func PublicMessage(err error) string {
+	if KindOf(err) == KindUser {
+		return err.Error()
+	}
+	return http.StatusText(HTTPStatus(err))
+}
+
+// Flatten expands keyvals so that every error value carrying Error attributes is
+// followed by those attributes, letting loggers record them as regular fields
+func Flatten(keyvals ...interface{}) []interface{} {
+	if !hasAttrs(keyvals) {
+		return keyvals
+	}
+
+	out := make([]interface{}, 0, len(keyvals)*2)
+	for i := 0; i < len(keyvals); i += 2 {
+		out = append(out, keyvals[i])
+		if i+1 >= len(keyvals) {
+			break
+		}
+		out = append(out, keyvals[i+1])
+		if err, ok := keyvals[i+1].(error); ok {
+			out = append(out, Attrs(err)...)
+		}
+	}
+	return out
+}
+
+func hasAttrs(keyvals []interface{}) bool {
+	for i := 1; i < len(keyvals); i += 2 {
+		if err, ok := keyvals[i].(error); ok && len(Attrs(err)) > 0 {
+			return true
+		}
+	}
+	return false
+}
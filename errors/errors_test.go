@@ -0,0 +1,48 @@
+package errors
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPStatus(t *testing.T) {
+	assert.Equal(t, http.StatusBadRequest, HTTPStatus(New("bad input").WithKind(KindUser)))
+	assert.Equal(t, http.StatusBadGateway, HTTPStatus(New("db down").WithKind(KindDependency)))
+	assert.Equal(t, http.StatusInternalServerError, HTTPStatus(New("boom")))
+	assert.Equal(t, http.StatusTooManyRequests, HTTPStatus(New("slow down").WithKind(KindUser).WithStatus(http.StatusTooManyRequests)))
+	assert.Equal(t, http.StatusInternalServerError, HTTPStatus(fmt.Errorf("plain")))
+
+	// kind and status survive wrapping
+	wrapped := fmt.Errorf("handler: %w", Wrap(New("not found").WithStatus(http.StatusNotFound), "lookup"))
+	assert.Equal(t, http.StatusNotFound, HTTPStatus(wrapped))
+}
+
+func TestAttrsAndFlatten(t *testing.T) {
+	inner := New("query failed", "table", "users")
+	outer := Wrap(inner, "load user", "user_id", 42)
+
+	assert.Equal(t, []interface{}{"user_id", 42, "table", "users"}, Attrs(outer))
+	assert.Equal(t, "load user: query failed", outer.Error())
+
+	keyvals := Flatten("request_id", "abc", "error", outer)
+	assert.Equal(t, []interface{}{"request_id", "abc", "error", outer, "user_id", 42, "table", "users"}, keyvals)
+
+	plain := []interface{}{"error", fmt.Errorf("plain")}
+	assert.Equal(t, plain, Flatten(plain...))
+}
+
+func TestStack(t *testing.T) {
+	err := New("boom")
+	assert.NotEmpty(t, err.StackTrace())
+	assert.True(t, strings.Contains(Stack(err), "TestStack"), "stack should start at the caller")
+	assert.Empty(t, Stack(fmt.Errorf("plain")))
+}
+
+func TestPublicMessage(t *testing.T) {
+	assert.Equal(t, "email is required", PublicMessage(New("email is required").WithKind(KindUser)))
+	assert.Equal(t, "Internal Server Error", PublicMessage(New("nil pointer in billing")))
+}
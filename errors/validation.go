@@ -0,0 +1,46 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+)
+
+// FieldError reports an invalid configuration field
+type FieldError struct {
+	// Field is the configuration key, e.g. "collector_endpoint"
+	Field string
+	// Value is the rejected value
+	Value interface{}
+	// Reason explains what is expected
+	Reason string
+}
+
+// Error implements the error interface
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s (got %q)", e.Field, e.Reason, fmt.Sprint(e.Value))
+}
+
+// FieldErrors returns every FieldError joined in err, e.g. by a config Validate method
+func FieldErrors(err error) []*FieldError {
+	var fieldErrors []*FieldError
+	var walk func(error)
+	walk = func(err error) {
+		if fe, ok := err.(*FieldError); ok {
+			fieldErrors = append(fieldErrors, fe)
+			return
+		}
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range joined.Unwrap() {
+				walk(e)
+			}
+			return
+		}
+		if inner := stderrors.Unwrap(err); inner != nil {
+			walk(inner)
+		}
+	}
+	if err != nil {
+		walk(err)
+	}
+	return fieldErrors
+}
@@ -0,0 +1,189 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/go-logr/logr"
+)
+
+// LevelEnabler is implemented by loggers that can report whether a level is enabled
+// in the underlying backend. Adapters use it to avoid building records that would be dropped.
+type LevelEnabler interface {
+	Enabled(ctx context.Context, level slog.Level) bool
+}
+
+// AsSlog returns a *slog.Logger whose records are routed through l, so libraries
+// expecting slog log through the configured backend (slog or logrus)
+func AsSlog(l Logger) *slog.Logger {
+	return slog.New(&slogHandler{lgr: l})
+}
+
+// AsLogr returns a logr.Logger whose records are routed through l.
+// V(0) maps to Info and any higher verbosity maps to Debug.
+func AsLogr(l Logger) logr.Logger {
+	return logr.New(&logrSink{lgr: l, level: logrLevel})
+}
+
+// logAt dispatches a record to the Logger method matching the slog level.
+// Levels above Error are logged as Error, adapters never panic on behalf of the caller.
+func logAt(ctx context.Context, l Logger, level slog.Level, msg string, keyvals ...interface{}) {
+	switch {
+	case level < slog.LevelInfo:
+		l.DebugContext(ctx, msg, keyvals...)
+	case level < slog.LevelWarn:
+		l.InfoContext(ctx, msg, keyvals...)
+	case level < slog.LevelError:
+		l.WarnContext(ctx, msg, keyvals...)
+	default:
+		l.ErrorContext(ctx, msg, keyvals...)
+	}
+}
+
+// levelEnabled asks the backend if the level is enabled, defaulting to true
+func levelEnabled(ctx context.Context, l Logger, level slog.Level) bool {
+	if e, ok := l.(LevelEnabler); ok {
+		return e.Enabled(ctx, level)
+	}
+	return true
+}
+
+// slogHandler implements slog.Handler on top of a Logger
+type slogHandler struct {
+	lgr    Logger
+	attrs  []interface{}
+	prefix string
+}
+
+// Enabled implements slog.Handler
+func (h *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return levelEnabled(ctx, h.lgr, level)
+}
+
+// Handle implements slog.Handler
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	keyvals := make([]interface{}, 0, len(h.attrs)+r.NumAttrs()*2)
+	keyvals = append(keyvals, h.attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		keyvals = appendAttr(keyvals, h.prefix, a)
+		return true
+	})
+	logAt(ctx, h.lgr, r.Level, r.Message, keyvals...)
+	return nil
+}
+
+// WithAttrs implements slog.Handler
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	keyvals := make([]interface{}, 0, len(h.attrs)+len(attrs)*2)
+	keyvals = append(keyvals, h.attrs...)
+	for _, a := range attrs {
+		keyvals = appendAttr(keyvals, h.prefix, a)
+	}
+	return &slogHandler{lgr: h.lgr, attrs: keyvals, prefix: h.prefix}
+}
+
+// WithGroup implements slog.Handler, groups are flattened into dotted keys
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &slogHandler{lgr: h.lgr, attrs: h.attrs, prefix: h.prefix + name + "."}
+}
+
+// appendAttr flattens an attribute into keyvals, expanding groups into dotted keys
+func appendAttr(keyvals []interface{}, prefix string, a slog.Attr) []interface{} {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return keyvals
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if a.Key != "" {
+			groupPrefix = prefix + a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			keyvals = appendAttr(keyvals, groupPrefix, ga)
+		}
+		return keyvals
+	}
+	return append(keyvals, prefix+a.Key, a.Value.Any())
+}
+
+// logrSink implements logr.LogSink on top of a Logger
+type logrSink struct {
+	lgr     Logger
+	name    string
+	keyvals []interface{}
+	// level maps a logr verbosity to a slog level
+	level func(v int) slog.Level
+}
+
+// logrLevel is the conventional logr mapping: V(0) is Info, everything else is Debug
+func logrLevel(v int) slog.Level {
+	if v <= 0 {
+		return slog.LevelInfo
+	}
+	return slog.LevelDebug
+}
+
+// otelLevel follows the verbosity used by the OTel SDK internal logger:
+// V(1) warnings, V(4) info, V(8) debug
+func otelLevel(v int) slog.Level {
+	switch {
+	case v <= 1:
+		return slog.LevelWarn
+	case v <= 4:
+		return slog.LevelInfo
+	default:
+		return slog.LevelDebug
+	}
+}
+
+// Init implements logr.LogSink
+func (s *logrSink) Init(logr.RuntimeInfo) {}
+
+// Enabled implements logr.LogSink
+func (s *logrSink) Enabled(v int) bool {
+	return levelEnabled(context.Background(), s.lgr, s.level(v))
+}
+
+// Info implements logr.LogSink
+func (s *logrSink) Info(v int, msg string, keysAndValues ...interface{}) {
+	logAt(context.Background(), s.lgr, s.level(v), msg, s.withKeyvals(keysAndValues)...)
+}
+
+// Error implements logr.LogSink
+func (s *logrSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	keyvals := s.withKeyvals(keysAndValues)
+	if err != nil {
+		keyvals = append(keyvals, "error", err)
+	}
+	s.lgr.ErrorContext(context.Background(), msg, keyvals...)
+}
+
+// WithValues implements logr.LogSink
+func (s *logrSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	c := *s
+	c.keyvals = append(append([]interface{}{}, s.keyvals...), keysAndValues...)
+	return &c
+}
+
+// WithName implements logr.LogSink
+func (s *logrSink) WithName(name string) logr.LogSink {
+	c := *s
+	if c.name != "" {
+		c.name += "/" + name
+	} else {
+		c.name = name
+	}
+	return &c
+}
+
+func (s *logrSink) withKeyvals(keysAndValues []interface{}) []interface{} {
+	keyvals := make([]interface{}, 0, len(s.keyvals)+len(keysAndValues)+2)
+	if s.name != "" {
+		keyvals = append(keyvals, "logger", s.name)
+	}
+	keyvals = append(keyvals, s.keyvals...)
+	return append(keyvals, keysAndValues...)
+}
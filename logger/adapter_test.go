@@ -0,0 +1,222 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// record is a call made to a recordingLogger
+type record struct {
+	level   string
+	msg     string
+	keyvals []interface{}
+}
+
+// recordingLogger records the calls it receives
+type recordingLogger struct {
+	records []record
+}
+
+func (l *recordingLogger) log(level, msg string, keyvals []interface{}) {
+	l.records = append(l.records, record{level: level, msg: msg, keyvals: keyvals})
+}
+
+func (l *recordingLogger) Info(msg string, keyvals ...interface{})  { l.log("info", msg, keyvals) }
+func (l *recordingLogger) Warn(msg string, keyvals ...interface{})  { l.log("warn", msg, keyvals) }
+func (l *recordingLogger) Error(msg string, keyvals ...interface{}) { l.log("error", msg, keyvals) }
+func (l *recordingLogger) Debug(msg string, keyvals ...interface{}) { l.log("debug", msg, keyvals) }
+func (l *recordingLogger) Panic(msg string, keyvals ...interface{}) { l.log("panic", msg, keyvals) }
+
+func (l *recordingLogger) InfoContext(_ context.Context, msg string, keyvals ...interface{}) {
+	l.log("info", msg, keyvals)
+}
+func (l *recordingLogger) WarnContext(_ context.Context, msg string, keyvals ...interface{}) {
+	l.log("warn", msg, keyvals)
+}
+func (l *recordingLogger) ErrorContext(_ context.Context, msg string, keyvals ...interface{}) {
+	l.log("error", msg, keyvals)
+}
+func (l *recordingLogger) DebugContext(_ context.Context, msg string, keyvals ...interface{}) {
+	l.log("debug", msg, keyvals)
+}
+func (l *recordingLogger) PanicContext(_ context.Context, msg string, keyvals ...interface{}) {
+	l.log("panic", msg, keyvals)
+}
+func (l *recordingLogger) UnderlyingLogger() interface{} { return l }
+
+// levelLogger is a recordingLogger whose backend drops records below minLevel
+type levelLogger struct {
+	recordingLogger
+	minLevel slog.Level
+}
+
+func (l *levelLogger) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= l.minLevel
+}
+
+// userValue is resolved by the adapter before it is flattened
+type userValue struct{ name string }
+
+func (u userValue) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("name", u.name))
+}
+
+func TestAsSlog_FlattensGroupsAndAttrs(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(l *slog.Logger)
+		want []interface{}
+	}{
+		{
+			name: "attrs",
+			log:  func(l *slog.Logger) { l.Info("msg", "k", "v", slog.Int("n", 1)) },
+			want: []interface{}{"k", "v", "n", int64(1)},
+		},
+		{
+			name: "with attrs",
+			log:  func(l *slog.Logger) { l.With("request_id", "r1").Info("msg", "k", "v") },
+			want: []interface{}{"request_id", "r1", "k", "v"},
+		},
+		{
+			name: "group attr",
+			log:  func(l *slog.Logger) { l.Info("msg", slog.Group("user", "id", 7, slog.Group("org", "id", 3))) },
+			want: []interface{}{"user.id", int64(7), "user.org.id", int64(3)},
+		},
+		{
+			name: "with group prefixes later attrs",
+			log: func(l *slog.Logger) {
+				l.With("app", "api").WithGroup("http").With("method", "GET").Info("msg", "status", 200)
+			},
+			want: []interface{}{"app", "api", "http.method", "GET", "http.status", int64(200)},
+		},
+		{
+			name: "empty group name is ignored",
+			log:  func(l *slog.Logger) { l.WithGroup("").Info("msg", "k", "v") },
+			want: []interface{}{"k", "v"},
+		},
+		{
+			name: "inline group and empty attrs",
+			log:  func(l *slog.Logger) { l.Info("msg", slog.Group("", "k", "v"), slog.Attr{}, slog.Group("empty")) },
+			want: []interface{}{"k", "v"},
+		},
+		{
+			name: "log valuer",
+			log:  func(l *slog.Logger) { l.Info("msg", "user", userValue{name: "jane"}) },
+			want: []interface{}{"user.name", "jane"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lgr := &recordingLogger{}
+			tt.log(AsSlog(lgr))
+
+			require.Len(t, lgr.records, 1)
+			assert.Equal(t, "msg", lgr.records[0].msg)
+			assert.Equal(t, tt.want, lgr.records[0].keyvals)
+		})
+	}
+}
+
+func TestAsSlog_LevelMapping(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  string
+	}{
+		{slog.LevelDebug - 4, "debug"},
+		{slog.LevelDebug, "debug"},
+		{slog.LevelInfo, "info"},
+		{slog.LevelInfo + 1, "info"},
+		{slog.LevelWarn, "warn"},
+		{slog.LevelError, "error"},
+		// adapters never panic on behalf of the caller
+		{LevelPanic, "error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			lgr := &recordingLogger{}
+			AsSlog(lgr).Log(context.Background(), tt.level, "msg")
+
+			require.Len(t, lgr.records, 1)
+			assert.Equal(t, tt.want, lgr.records[0].level)
+		})
+	}
+}
+
+func TestAsLogr_LevelMapping(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(lgr *recordingLogger)
+		want string
+	}{
+		{"V(0) is info", func(lgr *recordingLogger) { AsLogr(lgr).Info("msg") }, "info"},
+		{"V(1) is debug", func(lgr *recordingLogger) { AsLogr(lgr).V(1).Info("msg") }, "debug"},
+		{"V(5) is debug", func(lgr *recordingLogger) { AsLogr(lgr).V(5).Info("msg") }, "debug"},
+		{"error", func(lgr *recordingLogger) { AsLogr(lgr).V(3).Error(nil, "msg") }, "error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lgr := &recordingLogger{}
+			tt.log(lgr)
+
+			require.Len(t, lgr.records, 1)
+			assert.Equal(t, tt.want, lgr.records[0].level)
+		})
+	}
+}
+
+func TestLogrVerbosityToSlogLevel(t *testing.T) {
+	tests := []struct {
+		v          int
+		logr, otel slog.Level
+	}{
+		{0, slog.LevelInfo, slog.LevelWarn},
+		{1, slog.LevelDebug, slog.LevelWarn},
+		{4, slog.LevelDebug, slog.LevelInfo},
+		{8, slog.LevelDebug, slog.LevelDebug},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.logr, logrLevel(tt.v), "logr V(%d)", tt.v)
+		assert.Equal(t, tt.otel, otelLevel(tt.v), "otel V(%d)", tt.v)
+	}
+}
+
+func TestAsLogr_WithNameAndValues(t *testing.T) {
+	lgr := &recordingLogger{}
+	base := AsLogr(lgr)
+	named := base.WithName("controller").WithName("pods").WithValues("namespace", "default")
+
+	named.Info("reconciled", "pod", "web-0")
+	named.Error(errors.New("boom"), "failed", "pod", "web-1")
+	base.Info("unchanged")
+
+	require.Len(t, lgr.records, 3)
+	assert.Equal(t, []interface{}{"logger", "controller/pods", "namespace", "default", "pod", "web-0"},
+		lgr.records[0].keyvals)
+	assert.Equal(t, []interface{}{"logger", "controller/pods", "namespace", "default", "pod", "web-1",
+		"error", errors.New("boom")}, lgr.records[1].keyvals)
+	assert.Empty(t, lgr.records[2].keyvals, "WithName and WithValues must not modify the parent")
+}
+
+func TestAdapters_Enabled(t *testing.T) {
+	lgr := &levelLogger{minLevel: slog.LevelInfo}
+	sl, lr := AsSlog(lgr), AsLogr(lgr)
+
+	assert.False(t, sl.Enabled(context.Background(), slog.LevelDebug))
+	assert.True(t, sl.Enabled(context.Background(), slog.LevelInfo))
+	assert.True(t, lr.Enabled())
+	assert.False(t, lr.V(1).Enabled())
+
+	sl.Debug("dropped")
+	lr.V(1).Info("dropped")
+	assert.Empty(t, lgr.records, "disabled records are not built")
+
+	// loggers without LevelEnabler let the backend decide
+	plain := &recordingLogger{}
+	assert.True(t, AsSlog(plain).Enabled(context.Background(), slog.LevelDebug))
+	assert.True(t, AsLogr(plain).V(9).Enabled())
+}
@@ -0,0 +1,125 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DefaultOtelErrorInterval is the default window during which repeated OTel errors are logged once
+const DefaultOtelErrorInterval = time.Minute
+
+// maxTrackedOtelErrors bounds the number of distinct error messages kept for rate limiting
+const maxTrackedOtelErrors = 128
+
+// CaptureStdLog redirects the standard library log package into Log at the given level.
+// It works the same for every backend and returns a func restoring the previous output and flags.
+func CaptureStdLog(level slog.Level) (restore func()) {
+	prevWriter := log.Writer()
+	prevFlags := log.Flags()
+	prevPrefix := log.Prefix()
+
+	log.SetOutput(&stdLogWriter{level: level})
+	// timestamps and caller info are added by the backend
+	log.SetFlags(0)
+	log.SetPrefix("")
+
+	return func() {
+		log.SetOutput(prevWriter)
+		log.SetFlags(prevFlags)
+		log.SetPrefix(prevPrefix)
+	}
+}
+
+// stdLogWriter forwards each line written by the log package to Log
+type stdLogWriter struct {
+	level slog.Level
+}
+
+// Write implements io.Writer
+func (w *stdLogWriter) Write(p []byte) (int, error) {
+	msg := string(bytes.TrimRight(p, "\r\n"))
+	if msg == "" {
+		return len(p), nil
+	}
+	if lgr := L(); lgr != nil {
+		logAt(context.Background(), lgr, w.level, msg, "logger", "stdlib")
+	}
+	return len(p), nil
+}
+
+// OtelErrorHandler is an otel.ErrorHandler that logs SDK errors (failed exports, dropped spans)
+// through Log. Identical errors are logged at most once per interval, the number of
+// suppressed occurrences is reported on the next log line.
+type OtelErrorHandler struct {
+	interval time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*otelErrorEntry
+}
+
+type otelErrorEntry struct {
+	lastLogged time.Time
+	suppressed int
+}
+
+// NewOtelErrorHandler creates an OtelErrorHandler, a non positive interval uses DefaultOtelErrorInterval
+func NewOtelErrorHandler(interval time.Duration) *OtelErrorHandler {
+	if interval <= 0 {
+		interval = DefaultOtelErrorInterval
+	}
+	return &OtelErrorHandler{
+		interval: interval,
+		entries:  make(map[string]*otelErrorEntry),
+	}
+}
+
+// Handle implements otel.ErrorHandler
+func (h *OtelErrorHandler) Handle(err error) {
+	if err == nil {
+		return
+	}
+
+	suppressed, ok := h.allow(err.Error(), time.Now())
+	if !ok {
+		return
+	}
+
+	lgr := L()
+	if lgr == nil {
+		return
+	}
+	keyvals := []interface{}{"logger", "otel", "error", err}
+	if suppressed > 0 {
+		keyvals = append(keyvals, "suppressed", suppressed)
+	}
+	lgr.Error("opentelemetry error", keyvals...)
+}
+
+// allow reports whether key may be logged now and how many occurrences were suppressed since the last log
+func (h *OtelErrorHandler) allow(key string, now time.Time) (int, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	entry, ok := h.entries[key]
+	if !ok {
+		if len(h.entries) >= maxTrackedOtelErrors {
+			h.entries = make(map[string]*otelErrorEntry)
+		}
+		h.entries[key] = &otelErrorEntry{lastLogged: now}
+		return 0, true
+	}
+
+	if now.Sub(entry.lastLogged) < h.interval {
+		entry.suppressed++
+		return 0, false
+	}
+
+	suppressed := entry.suppressed
+	entry.lastLogged = now
+	entry.suppressed = 0
+	return suppressed, true
+}
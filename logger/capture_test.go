@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"log"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaptureStdLog(t *testing.T) {
+	lgr := &countingLogger{}
+	useLogger(t, lgr)
+
+	prev := log.Writer()
+	restore := CaptureStdLog(slog.LevelError)
+
+	log.Println("from the standard library")
+	log.Print("\n")
+	assert.Equal(t, int64(1), lgr.errors.Load(), "empty lines are dropped")
+
+	restore()
+	assert.Equal(t, prev, log.Writer())
+}
+
+func TestOtelErrorHandler_RateLimits(t *testing.T) {
+	lgr := &countingLogger{}
+	useLogger(t, lgr)
+
+	h := NewOtelErrorHandler(DefaultOtelErrorInterval)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.Handle(assert.AnError)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(1), lgr.errors.Load(), "identical errors are logged once per interval")
+}
@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// holder wraps the current logger, atomic.Pointer needs a concrete type
+// while loggers of different types are swapped in
+type holder struct {
+	Logger
+}
+
+// current is the logger installed by SetLogger
+var current atomic.Pointer[holder]
+
+// L returns the current logger. The returned value is not affected by later
+// calls to SetLogger, call L again to observe a swap.
+func L() Logger {
+	if h := current.Load(); h != nil {
+		return h.Logger
+	}
+	return nil
+}
+
+// globalLogger is the value of Log, it resolves the current logger on every call
+type globalLogger struct{}
+
+func (globalLogger) Info(msg string, keyvals ...interface{})  { L().Info(msg, keyvals...) }
+func (globalLogger) Warn(msg string, keyvals ...interface{})  { L().Warn(msg, keyvals...) }
+func (globalLogger) Error(msg string, keyvals ...interface{}) { L().Error(msg, keyvals...) }
+func (globalLogger) Debug(msg string, keyvals ...interface{}) { L().Debug(msg, keyvals...) }
+func (globalLogger) Panic(msg string, keyvals ...interface{}) { L().Panic(msg, keyvals...) }
+
+func (globalLogger) InfoContext(ctx context.Context, msg string, keyvals ...interface{}) {
+	L().InfoContext(ctx, msg, keyvals...)
+}
+
+func (globalLogger) WarnContext(ctx context.Context, msg string, keyvals ...interface{}) {
+	L().WarnContext(ctx, msg, keyvals...)
+}
+
+func (globalLogger) ErrorContext(ctx context.Context, msg string, keyvals ...interface{}) {
+	L().ErrorContext(ctx, msg, keyvals...)
+}
+
+func (globalLogger) DebugContext(ctx context.Context, msg string, keyvals ...interface{}) {
+	L().DebugContext(ctx, msg, keyvals...)
+}
+
+func (globalLogger) PanicContext(ctx context.Context, msg string, keyvals ...interface{}) {
+	L().PanicContext(ctx, msg, keyvals...)
+}
+
+func (globalLogger) UnderlyingLogger() interface{} {
+	return L().UnderlyingLogger()
+}
+
+// Enabled implements LevelEnabler for the current logger
+func (globalLogger) Enabled(ctx context.Context, level slog.Level) bool {
+	return levelEnabled(ctx, L(), level)
+}
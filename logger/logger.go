@@ -4,26 +4,31 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/go-logr/logr"
 	"github.com/ubin/go-observability/logger/loggerfactory/config"
 	"github.com/ubin/go-observability/logger/loggerfactory/defaultlogger"
+	"go.opentelemetry.io/otel"
 )
 
-// Log is a package level variable, every program should access logging function through "Log"
-var Log Logger
+// Log is a package level variable, every program should access logging function through "Log".
+// It forwards every call to the logger installed with SetLogger (see L), so it is safe to use
+// while the logger is being swapped. Do not assign it, use SetLogger instead.
+var Log Logger = globalLogger{}
 
 func init() {
-	if Log != nil {
-		return
-	}
-	// //set the Log to default logger (slog) with default configuration
-	var err error
-	if Log, err = defaultlogger.New(config.LogEnvDev, defaultlogger.Config{}); err != nil {
+	// set the default logger (slog) with default configuration,
+	// silently since importing the package must not produce output
+	lgr, err := defaultlogger.New(config.LogEnvDev, defaultlogger.Config{})
+	if err != nil {
 		fmt.Println("Unable to initialize default logger")
 		return
 	}
-	Log.Info("default logger [slog] initialized")
+	current.Store(&holder{lgr})
 }
 
+// LevelPanic is the slog level of Panic records, above slog.LevelError
+const LevelPanic = config.LevelPanic
+
 type Config interface {
 	// log library name
 	GetCode() string
@@ -87,7 +92,13 @@ type Logger interface {
 	UnderlyingLoggerProvider
 }
 
-// SetLogger is the setter for log variable, it should be the only way to assign value to log
+// SetLogger atomically replaces the logger behind Log and L, it should be the only way to assign
+// value to log. It is safe to call while other goroutines are logging, e.g. on config reload.
+// It also routes the OpenTelemetry SDK internal logger through newLogger. A nil logger is ignored.
 func SetLogger(newLogger Logger) {
-	Log = newLogger
+	if newLogger == nil {
+		return
+	}
+	current.Store(&holder{newLogger})
+	otel.SetLogger(logr.New(&logrSink{lgr: newLogger, name: "otel", level: otelLevel}))
 }
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingLogger counts calls per level, it is safe for concurrent use
+type countingLogger struct {
+	infos  atomic.Int64
+	errors atomic.Int64
+}
+
+func (l *countingLogger) Info(msg string, keyvals ...interface{})  { l.infos.Add(1) }
+func (l *countingLogger) Warn(msg string, keyvals ...interface{})  {}
+func (l *countingLogger) Error(msg string, keyvals ...interface{}) { l.errors.Add(1) }
+func (l *countingLogger) Debug(msg string, keyvals ...interface{}) {}
+func (l *countingLogger) Panic(msg string, keyvals ...interface{}) { panic(msg) }
+
+func (l *countingLogger) InfoContext(ctx context.Context, msg string, keyvals ...interface{}) {
+	l.infos.Add(1)
+}
+func (l *countingLogger) WarnContext(ctx context.Context, msg string, keyvals ...interface{}) {}
+func (l *countingLogger) ErrorContext(ctx context.Context, msg string, keyvals ...interface{}) {
+	l.errors.Add(1)
+}
+func (l *countingLogger) DebugContext(ctx context.Context, msg string, keyvals ...interface{}) {}
+func (l *countingLogger) PanicContext(ctx context.Context, msg string, keyvals ...interface{}) {
+	panic(msg)
+}
+func (l *countingLogger) UnderlyingLogger() interface{} { return l }
+
+// useLogger installs lgr for the duration of the test
+func useLogger(t *testing.T, lgr Logger) {
+	t.Helper()
+	prev := L()
+	SetLogger(lgr)
+	t.Cleanup(func() { SetLogger(prev) })
+}
+
+func TestSetLogger_ConcurrentLoggingAndSwapping(t *testing.T) {
+	first, second := &countingLogger{}, &countingLogger{}
+	useLogger(t, first)
+
+	const goroutines, iterations = 8, 500
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+
+	// swap loggers as fast as possible while logging, like a config reload would
+	swapped := make(chan struct{})
+	go func() {
+		defer close(swapped)
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if i%2 == 0 {
+				SetLogger(second)
+			} else {
+				SetLogger(first)
+			}
+		}
+	}()
+
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				Log.Info("via Log")
+				L().InfoContext(context.Background(), "via L")
+				AsSlog(Log).Info("via slog adapter")
+			}
+		}()
+	}
+	wg.Wait()
+	close(stop)
+	<-swapped
+
+	total := first.infos.Load() + second.infos.Load()
+	assert.Equal(t, int64(goroutines*iterations*3), total, "no log call should be lost while swapping")
+}
+
+func TestSetLogger_IgnoresNil(t *testing.T) {
+	lgr := &countingLogger{}
+	useLogger(t, lgr)
+
+	SetLogger(nil)
+	assert.Same(t, lgr, L())
+}
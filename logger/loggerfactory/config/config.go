@@ -1,10 +1,27 @@
 package config
 
+import "log/slog"
+
 const (
 	LOGRUS = "logrus"
 	SLOG   = "slog"
 )
 
+// DefaultCode is the backend used when the configuration leaves the code empty
+const DefaultCode = SLOG
+
+// Code returns the backend of a configuration code, DefaultCode when it is empty
+func Code(code string) string {
+	if code == "" {
+		return DefaultCode
+	}
+	return code
+}
+
+// LevelPanic is the slog level of Panic records, slog does not define one. It is shared by
+// the backends and the adapters of the logger package.
+const LevelPanic = slog.Level(15)
+
 // LogEnv type
 type LogEnv int
 
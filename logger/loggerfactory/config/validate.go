@@ -0,0 +1,78 @@
+package config
+
+import (
+	"errors"
+	"strings"
+
+	obserrors "github.com/ubin/go-observability/errors"
+)
+
+// validLevels are the level names understood by each backend
+var validLevels = map[string][]string{
+	LOGRUS: {"trace", "debug", "info", "warn", "warning", "error", "fatal", "panic"},
+	SLOG:   {"debug", "info", "warn", "warning", "error"},
+}
+
+// levelsReason lists the level names of each backend in error messages, warning is an alias
+var levelsReason = map[string]string{
+	LOGRUS: "must be one of trace, debug, info, warn, error, fatal, panic",
+	SLOG:   "must be one of debug, info, warn, error",
+}
+
+// validFormatters are the formatter names understood by the slog backend
+var validFormatters = []string{"text", "json"}
+
+// Validatable is the part of a logger configuration checked by Validate
+type Validatable interface {
+	GetCode() string
+	GetFormatter() string
+	GetLevel() string
+	GetFileEnabled() bool
+	GetFilename() string
+	GetMaxSize() int
+	GetMaxBackups() int
+	GetMaxAge() int
+}
+
+// Validate checks a logger configuration and returns every invalid field joined in one error,
+// each one being an *errors.FieldError. An empty code selects DefaultCode, levels are checked
+// against the names the selected backend understands.
+func Validate(cfg Validatable) error {
+	var errs []error
+
+	code := Code(cfg.GetCode())
+	if code != LOGRUS && code != SLOG {
+		errs = append(errs, &obserrors.FieldError{Field: "code", Value: code, Reason: "must be one of logrus, slog"})
+		// still report the other fields, checked as for the default backend
+		code = DefaultCode
+	}
+	if formatter := cfg.GetFormatter(); formatter != "" && !oneOf(formatter, validFormatters) {
+		errs = append(errs, &obserrors.FieldError{Field: "formatter", Value: formatter, Reason: "must be one of TEXT, JSON"})
+	}
+	if level := cfg.GetLevel(); level != "" && !oneOf(level, validLevels[code]) {
+		errs = append(errs, &obserrors.FieldError{Field: "level", Value: level, Reason: levelsReason[code]})
+	}
+	if cfg.GetFileEnabled() && cfg.GetFilename() == "" {
+		errs = append(errs, &obserrors.FieldError{Field: "filename", Value: "", Reason: "is required when file_enabled is true"})
+	}
+	if cfg.GetMaxSize() < 0 {
+		errs = append(errs, &obserrors.FieldError{Field: "max_size", Value: cfg.GetMaxSize(), Reason: "must not be negative"})
+	}
+	if cfg.GetMaxBackups() < 0 {
+		errs = append(errs, &obserrors.FieldError{Field: "max_backups", Value: cfg.GetMaxBackups(), Reason: "must not be negative"})
+	}
+	if cfg.GetMaxAge() < 0 {
+		errs = append(errs, &obserrors.FieldError{Field: "max_age", Value: cfg.GetMaxAge(), Reason: "must not be negative"})
+	}
+
+	return errors.Join(errs...)
+}
+
+func oneOf(value string, allowed []string) bool {
+	for _, a := range allowed {
+		if strings.EqualFold(value, a) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	obserrors "github.com/ubin/go-observability/errors"
+)
+
+type testConfig struct {
+	code, formatter, level, filename string
+	fileEnabled                      bool
+	maxSize                          int
+}
+
+func (c testConfig) GetCode() string      { return c.code }
+func (c testConfig) GetFormatter() string { return c.formatter }
+func (c testConfig) GetLevel() string     { return c.level }
+func (c testConfig) GetFileEnabled() bool { return c.fileEnabled }
+func (c testConfig) GetFilename() string  { return c.filename }
+func (c testConfig) GetMaxSize() int      { return c.maxSize }
+func (c testConfig) GetMaxBackups() int   { return 0 }
+func (c testConfig) GetMaxAge() int       { return 0 }
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(testConfig{code: SLOG, formatter: "json", level: "DEBUG"}))
+	assert.NoError(t, Validate(testConfig{code: LOGRUS, level: "warning"}))
+
+	err := Validate(testConfig{code: "zap", formatter: "xml", level: "verbose", fileEnabled: true, maxSize: -1})
+	var names []string
+	for _, fe := range obserrors.FieldErrors(err) {
+		names = append(names, fe.Field)
+	}
+	assert.Equal(t, []string{"code", "formatter", "level", "filename", "max_size"}, names)
+	assert.Contains(t, err.Error(), `level: must be one of debug, info, warn, error (got "verbose")`)
+}
+
+func TestValidate_LevelsPerBackend(t *testing.T) {
+	tests := []struct {
+		code, level string
+		valid       bool
+	}{
+		{code: LOGRUS, level: "trace", valid: true},
+		{code: LOGRUS, level: "FATAL", valid: true},
+		{code: LOGRUS, level: "panic", valid: true},
+		{code: SLOG, level: "trace"},
+		{code: SLOG, level: "fatal"},
+		{code: SLOG, level: "warning", valid: true},
+		// an empty code is the default backend
+		{code: "", level: "debug", valid: true},
+		{code: "", level: "panic"},
+	}
+	for _, tt := range tests {
+		err := Validate(testConfig{code: tt.code, level: tt.level})
+		if tt.valid {
+			assert.NoError(t, err, "%q accepts %q", tt.code, tt.level)
+			continue
+		}
+		if assert.Error(t, err, "%q rejects %q", tt.code, tt.level) {
+			assert.Equal(t, "level", obserrors.FieldErrors(err)[0].Field)
+		}
+	}
+
+	err := Validate(testConfig{code: LOGRUS, level: "verbose"})
+	assert.Contains(t, err.Error(), "must be one of trace, debug, info, warn, error, fatal, panic")
+}
+
+func TestValidate_EmptyCodeIsDefault(t *testing.T) {
+	assert.Equal(t, DefaultCode, Code(""))
+	assert.Equal(t, LOGRUS, Code(LOGRUS))
+	assert.NoError(t, Validate(testConfig{}))
+}
@@ -74,6 +74,11 @@ func (cfg Config) GetLocalTime() bool {
 	return cfg.LocalTime
 }
 
+// Validate returns every invalid field of the configuration joined in one error
+func (cfg Config) Validate() error {
+	return config.Validate(cfg)
+}
+
 func New(env config.LogEnv, cfg Config) (slog.LoggerWrapper, error) {
 	//TODO : customizations
 	return slog.New(env, cfg)
@@ -9,8 +9,23 @@ import (
 	loggerslog "github.com/ubin/go-observability/logger/loggerfactory/slog"
 )
 
-// Register initializes the logger based on the configuration.
+// Register initializes the logger based on the configuration and installs it as logger.Log.
 func Register(cfg logger.Config, env string) error {
+	lgr, err := New(cfg, env)
+	if err != nil {
+		return err
+	}
+	logger.SetLogger(lgr)
+	return nil
+}
+
+// New creates a logger based on the configuration without touching logger.Log,
+// so libraries and tests can hold several independently configured loggers.
+// The configuration is validated first, whatever the backend.
+func New(cfg logger.Config, env string) (logger.Logger, error) {
+	if err := logconfig.Validate(cfg); err != nil {
+		return nil, fmt.Errorf("invalid logger config: %w", err)
+	}
 
 	logEnv := logconfig.LogEnvDev
 	if env == "production" || env == "prod" {
@@ -19,14 +34,13 @@ func Register(cfg logger.Config, env string) error {
 
 	lgr, err := getLogger(cfg, logEnv)
 	if err != nil {
-		return fmt.Errorf("error initializing logger: %s", err)
+		return nil, fmt.Errorf("error initializing logger: %s", err)
 	}
-	logger.SetLogger(lgr)
-	return nil
+	return lgr, nil
 }
 
 func getLogger(cfg logger.Config, env logconfig.LogEnv) (logger.Logger, error) {
-	switch cfg.GetCode() {
+	switch logconfig.Code(cfg.GetCode()) {
 	case logconfig.LOGRUS:
 		logrusFactory := &LogrusFactory{}
 		return logrusFactory.CreateLogger(cfg, env)
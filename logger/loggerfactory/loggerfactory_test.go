@@ -0,0 +1,37 @@
+package loggerfactory
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ubin/go-observability/logger"
+	logconfig "github.com/ubin/go-observability/logger/loggerfactory/config"
+	"github.com/ubin/go-observability/logger/loggerfactory/defaultlogger"
+	loggerslog "github.com/ubin/go-observability/logger/loggerfactory/slog"
+)
+
+func TestNew_EmptyCodeIsDefaultBackend(t *testing.T) {
+	require.NoError(t, defaultlogger.Config{}.Validate())
+
+	lgr, err := New(defaultlogger.Config{Level: "debug"}, "")
+	require.NoError(t, err)
+	assert.IsType(t, loggerslog.LoggerWrapper{}, lgr)
+}
+
+func TestNew_LogrusLevels(t *testing.T) {
+	for level, enabled := range map[string]slog.Level{
+		"trace": slog.LevelDebug,
+		"fatal": logger.LevelPanic,
+		"panic": logger.LevelPanic,
+	} {
+		lgr, err := New(defaultlogger.Config{Code: logconfig.LOGRUS, Level: level}, "")
+		require.NoError(t, err, level)
+		assert.True(t, lgr.(logger.LevelEnabler).Enabled(context.Background(), enabled), level)
+	}
+
+	_, err := New(defaultlogger.Config{Code: logconfig.SLOG, Level: "trace"}, "")
+	assert.Error(t, err, "slog has no trace level")
+}
@@ -4,12 +4,13 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"os"
 	"runtime"
 	"strings"
 
 	"github.com/sirupsen/logrus"
+	obserrors "github.com/ubin/go-observability/errors"
 	"github.com/ubin/go-observability/logger"
 	"github.com/ubin/go-observability/logger/loggerfactory/config"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -25,7 +26,28 @@ func (l LoggerWrapper) UnderlyingLogger() interface{} {
 
 }
 
+// Enabled reports whether logrus emits records at the given slog level
+func (l LoggerWrapper) Enabled(_ context.Context, level slog.Level) bool {
+	return l.logger.IsLevelEnabled(toLogrusLevel(level))
+}
+
+func toLogrusLevel(level slog.Level) logrus.Level {
+	switch {
+	case level < slog.LevelInfo:
+		return logrus.DebugLevel
+	case level < slog.LevelWarn:
+		return logrus.InfoLevel
+	case level < slog.LevelError:
+		return logrus.WarnLevel
+	case level < logger.LevelPanic:
+		return logrus.ErrorLevel
+	default:
+		return logrus.PanicLevel
+	}
+}
+
 func toFields(keyvals ...interface{}) logrus.Fields {
+	keyvals = obserrors.Flatten(keyvals...)
 	fields := make(logrus.Fields, len(keyvals)/2)
 	for i := 0; i < len(keyvals); i += 2 {
 		// Handle odd-length keyvals slices
@@ -82,6 +104,8 @@ func (l LoggerWrapper) PanicContext(ctx context.Context, msg string, keyvals ...
 	l.logger.WithContext(ctx).WithFields(toFields(keyvals...)).Panic(msg)
 }
 
+// New creates a logrus backed logger. Unlike earlier versions it does not redirect the
+// standard library log package, call logger.CaptureStdLog to route it through the logger.
 func New(env config.LogEnv, cfg logger.Config) (logger.Logger, error) {
 	w := io.Writer(os.Stdout)
 
@@ -116,8 +140,6 @@ func New(env config.LogEnv, cfg logger.Config) (logger.Logger, error) {
 
 	// logger := rus.WithField("logger", "app")
 	logger := LoggerWrapper{rus}
-	logger.Warn("Logrus initialized...")
-	log.SetOutput(logger.logger.Writer())
 
 	return logger, nil
 }
@@ -143,6 +165,10 @@ func formatFilePath(path string) string {
 }
 
 func customizeLogFromConfig(log *logrus.Logger, cfg logger.Config) error {
+	// an empty level keeps the default (info), like the slog backend
+	if cfg.GetLevel() == "" {
+		return nil
+	}
 	l := &log.Level
 	err := l.UnmarshalText([]byte(cfg.GetLevel()))
 	if err != nil {
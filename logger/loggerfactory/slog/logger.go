@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	obserrors "github.com/ubin/go-observability/errors"
 	"github.com/ubin/go-observability/logger/loggerfactory/config"
 	otelslog "github.com/ubin/go-observability/telemetry/log/slog"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -32,7 +33,7 @@ type Config interface {
 }
 
 // custom level for panic, as slog doesn't define panic level by default
-const LevelPanic = slog.Level(15)
+const LevelPanic = config.LevelPanic
 
 // LoggerWrapper is a logger that uses the Go standard library's slog package
 type LoggerWrapper struct {
@@ -59,29 +60,34 @@ func (l LoggerWrapper) Panic(msg string, keyvals ...interface{}) {
 }
 
 func (l LoggerWrapper) InfoContext(ctx context.Context, msg string, keyvals ...interface{}) {
-	otelslog.AddLogToSpan(ctx, slog.LevelInfo, msg, keyvals...)
-	l.lgr.Log(ctx, slog.LevelInfo, msg, keyvals...)
+	l.log(ctx, slog.LevelInfo, msg, keyvals...)
 }
 func (l LoggerWrapper) WarnContext(ctx context.Context, msg string, keyvals ...interface{}) {
-	otelslog.AddLogToSpan(ctx, slog.LevelWarn, msg, keyvals...)
-	l.lgr.Log(ctx, slog.LevelWarn, msg, keyvals...)
+	l.log(ctx, slog.LevelWarn, msg, keyvals...)
 }
 func (l LoggerWrapper) DebugContext(ctx context.Context, msg string, keyvals ...interface{}) {
-	otelslog.AddLogToSpan(ctx, slog.LevelDebug, msg, keyvals...)
-	l.lgr.Log(ctx, slog.LevelDebug, msg, keyvals...)
-
+	l.log(ctx, slog.LevelDebug, msg, keyvals...)
 }
 func (l LoggerWrapper) ErrorContext(ctx context.Context, msg string, keyvals ...interface{}) {
-	otelslog.AddLogToSpan(ctx, slog.LevelError, msg, keyvals...)
-	l.lgr.Log(ctx, slog.LevelError, msg, keyvals...)
-
+	l.log(ctx, slog.LevelError, msg, keyvals...)
 }
 func (l LoggerWrapper) PanicContext(ctx context.Context, msg string, keyvals ...interface{}) {
-	otelslog.AddLogToSpan(ctx, LevelPanic, msg, keyvals...)
-	l.lgr.Log(ctx, LevelPanic, msg, keyvals...)
+	l.log(ctx, LevelPanic, msg, keyvals...)
 	panic(msg)
 }
 
+// log flattens error attributes into keyvals, mirrors the record on the current span and logs it
+func (l LoggerWrapper) log(ctx context.Context, level slog.Level, msg string, keyvals ...interface{}) {
+	keyvals = obserrors.Flatten(keyvals...)
+	otelslog.AddLogToSpan(ctx, level, msg, keyvals...)
+	l.lgr.Log(ctx, level, msg, keyvals...)
+}
+
+// Enabled reports whether the handler emits records at the given level
+func (l LoggerWrapper) Enabled(ctx context.Context, level slog.Level) bool {
+	return l.lgr.Enabled(ctx, level)
+}
+
 func (l LoggerWrapper) UnderlyingLogger() interface{} {
 	return l.lgr
 
@@ -127,12 +133,14 @@ func New(env config.LogEnv, cfg Config) (LoggerWrapper, error) {
 
 	lgr := LoggerWrapper{sl} //.WithGroup("app")
 
-	lgr.Warn("Slog initialized...")
-
 	return lgr, nil
 }
 
 func ParseLevel(s string) (slog.Level, error) {
+	// accept the logrus spelling so both backends understand the same names
+	if strings.EqualFold(s, "warning") {
+		s = "warn"
+	}
 	var level slog.Level
 	var err = level.UnmarshalText([]byte(s))
 	return level, err
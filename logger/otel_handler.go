@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 
+	obserrors "github.com/ubin/go-observability/errors"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -104,11 +105,13 @@ func AddLogToSpan(ctx context.Context, level slog.Level, msg string, keyvals ...
 
 	// Record error or add event to span
 	if level == slog.LevelError {
-		if capturedError != nil {
-			span.RecordError(capturedError, trace.WithAttributes(attrs...))
-		} else {
-			span.RecordError(errors.New(msg), trace.WithAttributes(attrs...))
+		if capturedError == nil {
+			capturedError = errors.New(msg)
 		}
+		if stack := obserrors.Stack(capturedError); stack != "" {
+			attrs = append(attrs, attribute.String("exception.stacktrace", stack))
+		}
+		span.RecordError(capturedError, trace.WithAttributes(attrs...))
 	} else {
 		span.AddEvent("log", trace.WithAttributes(attrs...))
 	}
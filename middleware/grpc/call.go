@@ -0,0 +1,105 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// call tracks an instrumented RPC from start to completion
+type call struct {
+	config     *Config
+	span       trace.Span
+	fullMethod string
+	requestID  string
+	start      time.Time
+	server     bool
+	// panicked is set once recovered recorded the panic on the span
+	panicked bool
+}
+
+// startSpan starts the span of an RPC, named "package.Service/Method" with rpc.* attributes
+func startSpan(ctx context.Context, config *Config, fullMethod string, kind trace.SpanKind, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	service, method := splitMethod(fullMethod)
+	attrs = append([]attribute.KeyValue{
+		attribute.String("rpc.system", "grpc"),
+		attribute.String("rpc.service", service),
+		attribute.String("rpc.method", method),
+	}, attrs...)
+	return config.tracer().Start(ctx, strings.TrimPrefix(fullMethod, "/"),
+		trace.WithSpanKind(kind),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// end records the status code on the span, logs the call and ends the span
+func (c *call) end(ctx context.Context, err error) {
+	duration := time.Since(c.start)
+	st := status.Convert(err)
+	failed := c.failed(st.Code())
+
+	c.span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(st.Code())))
+	if failed {
+		// the panic was already recorded with its stack trace
+		if !c.panicked {
+			c.span.RecordError(err)
+		}
+		c.span.SetStatus(otelcodes.Error, st.Message())
+		c.span.SetAttributes(attribute.Bool("error", true))
+	}
+	c.span.End()
+
+	// clients only log failed calls, servers log every completed request
+	if !c.config.logging() || (!c.server && err == nil) {
+		return
+	}
+	attrs := []any{
+		"method", c.fullMethod,
+		"code", st.Code().String(),
+		"duration_ms", duration.Milliseconds(),
+	}
+	if c.requestID != "" {
+		attrs = append(attrs, "request_id", c.requestID)
+	}
+	if err != nil {
+		attrs = append(attrs, "error", st.Message())
+	}
+
+	switch {
+	case !c.server:
+		c.config.Logger.ErrorContext(ctx, "gRPC call failed", attrs...)
+	case failed:
+		c.config.Logger.ErrorContext(ctx, "gRPC request completed", attrs...)
+	default:
+		c.config.Logger.InfoContext(ctx, "gRPC request completed", attrs...)
+	}
+}
+
+// failed reports whether code marks the span as an error. Servers follow the OpenTelemetry
+// semantic conventions and only flag codes caused by the server, clients flag every non OK code.
+func (c *call) failed(code codes.Code) bool {
+	if !c.server {
+		return code != codes.OK
+	}
+	switch code {
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented, codes.Internal, codes.Unavailable, codes.DataLoss:
+		return true
+	default:
+		return false
+	}
+}
+
+// splitMethod splits "/package.Service/Method" into its service and method names
+func splitMethod(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "", fullMethod
+}
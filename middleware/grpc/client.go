@@ -0,0 +1,118 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryClientInterceptor returns a unary client interceptor that creates client spans and
+// injects the trace context and request ID in the outgoing metadata
+func UnaryClientInterceptor(config *Config) grpc.UnaryClientInterceptor {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if config.shouldSkipMethod(method) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		ctx, c := startClientCall(ctx, config, method, cc.Target())
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		c.end(ctx, err)
+		return err
+	}
+}
+
+// StreamClientInterceptor returns a stream client interceptor that creates client spans and
+// injects the trace context and request ID in the outgoing metadata. The span ends once the
+// stream returns an error or io.EOF, so streams must be read until they finish.
+func StreamClientInterceptor(config *Config) grpc.StreamClientInterceptor {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if config.shouldSkipMethod(method) {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+
+		ctx, c := startClientCall(ctx, config, method, cc.Target())
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			c.end(ctx, err)
+			return nil, err
+		}
+		return &clientStream{
+			ClientStream:  stream,
+			serverStreams: desc.ServerStreams,
+			end:           func(err error) { c.end(ctx, err) },
+		}, nil
+	}
+}
+
+// clientStream ends the call when the server finished the stream
+type clientStream struct {
+	grpc.ClientStream
+	serverStreams bool
+
+	once sync.Once
+	end  func(err error)
+}
+
+// RecvMsg implements grpc.ClientStream
+func (s *clientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case err == io.EOF:
+		s.finish(nil)
+	case err != nil:
+		s.finish(err)
+	case !s.serverStreams:
+		// unary responses and client streams receive a single message
+		s.finish(nil)
+	}
+	return err
+}
+
+func (s *clientStream) finish(err error) {
+	s.once.Do(func() { s.end(err) })
+}
+
+// startClientCall starts the client span and injects the trace context and request ID in the outgoing metadata
+func startClientCall(ctx context.Context, config *Config, fullMethod, target string) (context.Context, *call) {
+	ctx, span := startSpan(ctx, config, fullMethod, trace.SpanKindClient,
+		attribute.String("net.peer.name", target))
+
+	// metadata in the context must not be modified, inject into a copy
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	config.propagator().Inject(ctx, metadataCarrier(md))
+
+	requestID := RequestIDFromContext(ctx)
+	if requestID != "" && len(md.Get(RequestIDKey)) == 0 {
+		md.Set(RequestIDKey, requestID)
+	}
+	if requestID != "" {
+		span.SetAttributes(attribute.String("rpc.request_id", requestID))
+	}
+
+	return metadata.NewOutgoingContext(ctx, md), &call{
+		config:     config,
+		span:       span,
+		fullMethod: fullMethod,
+		requestID:  requestID,
+		start:      time.Now(),
+	}
+}
@@ -0,0 +1,82 @@
+// Package grpc provides OpenTelemetry interceptors for gRPC servers and clients, mirroring the
+// HTTP middleware: trace context propagation, rpc.* span attributes, request IDs, panic
+// recovery and completion logs.
+package grpc
+
+import (
+	"github.com/ubin/go-observability/logger"
+	"github.com/ubin/go-observability/middleware/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// Config holds configuration for the gRPC interceptors
+type Config struct {
+	// TracerProvider is the OpenTelemetry tracer provider, e.g. from telemetry.InitTracer
+	// or the reloadable one from reload.Manager
+	// If nil, including a nil *sdktrace.TracerProvider, tracing will be skipped
+	TracerProvider trace.TracerProvider
+
+	// Logger is used for logging completed calls
+	// If nil, logging will be skipped
+	Logger logger.ContextLogger
+
+	// Propagator extracts and injects the trace context in gRPC metadata
+	// If nil, the global OpenTelemetry propagator is used
+	Propagator propagation.TextMapPropagator
+
+	// ServiceName is the name of the service for tracing (defaults to "grpc-server")
+	ServiceName string
+
+	// SkipMethods are full method names to exclude (e.g., /grpc.health.v1.Health/Check)
+	SkipMethods []string
+
+	// SkipLogging disables call logging if true
+	SkipLogging bool
+
+	// GenerateRequestID enables request ID generation on the server and the x-request-id header
+	GenerateRequestID bool
+}
+
+// DefaultConfig returns a config with sensible defaults
+func DefaultConfig() *Config {
+	return &Config{
+		ServiceName:       "grpc-server",
+		SkipMethods:       []string{},
+		SkipLogging:       false,
+		GenerateRequestID: true,
+	}
+}
+
+// shouldSkipMethod checks if a method should be excluded from instrumentation
+func (c *Config) shouldSkipMethod(fullMethod string) bool {
+	for _, method := range c.SkipMethods {
+		if fullMethod == method {
+			return true
+		}
+	}
+	return false
+}
+
+// propagator returns the configured propagator or the global one
+func (c *Config) propagator() propagation.TextMapPropagator {
+	if c.Propagator != nil {
+		return c.Propagator
+	}
+	return otel.GetTextMapPropagator()
+}
+
+// tracer returns the tracer of the configured provider, a no-op tracer when tracing is disabled
+func (c *Config) tracer() trace.Tracer {
+	if !tracing.Enabled(c.TracerProvider) {
+		return noop.NewTracerProvider().Tracer("")
+	}
+	return c.TracerProvider.Tracer(c.ServiceName)
+}
+
+// logging reports whether completed calls are logged
+func (c *Config) logging() bool {
+	return c.Logger != nil && !c.SkipLogging
+}
@@ -0,0 +1,235 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// healthServer records the request ID seen by handlers and panics for the "panic" service
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+	requestID string
+}
+
+func (s *healthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	s.requestID = RequestIDFromContext(ctx)
+	switch req.Service {
+	case "panic":
+		panic("boom")
+	case "missing":
+		return nil, status.Error(codes.NotFound, "unknown service")
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func (s *healthServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	s.requestID = RequestIDFromContext(stream.Context())
+	for _, st := range []healthpb.HealthCheckResponse_ServingStatus{
+		healthpb.HealthCheckResponse_NOT_SERVING,
+		healthpb.HealthCheckResponse_SERVING,
+	} {
+		if err := stream.Send(&healthpb.HealthCheckResponse{Status: st}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// errorLogger keeps the key/values of the error logs, the interceptors log from the RPC goroutines
+type errorLogger struct {
+	mu     sync.Mutex
+	errors map[string][]interface{}
+}
+
+func (l *errorLogger) InfoContext(context.Context, string, ...interface{})  {}
+func (l *errorLogger) WarnContext(context.Context, string, ...interface{})  {}
+func (l *errorLogger) DebugContext(context.Context, string, ...interface{}) {}
+func (l *errorLogger) PanicContext(context.Context, string, ...interface{}) {}
+func (l *errorLogger) ErrorContext(_ context.Context, msg string, keyvals ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errors[msg] = keyvals
+}
+
+func (l *errorLogger) get(msg string) []interface{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.errors[msg]
+}
+
+func setup(t *testing.T, opts ...func(*Config)) (healthpb.HealthClient, *healthServer, *tracetest.SpanRecorder) {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+
+	config := DefaultConfig()
+	config.TracerProvider = tp
+	config.Propagator = propagation.TraceContext{}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor(config)),
+		grpc.StreamInterceptor(StreamServerInterceptor(config)),
+	)
+	health := &healthServer{}
+	healthpb.RegisterHealthServer(server, health)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor(config)),
+		grpc.WithStreamInterceptor(StreamClientInterceptor(config)),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return healthpb.NewHealthClient(conn), health, recorder
+}
+
+// spansByKind returns the client and server spans, the server span ends before the client one
+func spansByKind(t *testing.T, recorder *tracetest.SpanRecorder) (client, server sdktrace.ReadOnlySpan) {
+	t.Helper()
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	for _, span := range spans {
+		switch span.SpanKind() {
+		case trace.SpanKindClient:
+			client = span
+		case trace.SpanKindServer:
+			server = span
+		}
+	}
+	require.NotNil(t, client)
+	require.NotNil(t, server)
+	return client, server
+}
+
+func attr(span sdktrace.ReadOnlySpan, key string) any {
+	for _, kv := range span.Attributes() {
+		if string(kv.Key) == key {
+			return kv.Value.AsInterface()
+		}
+	}
+	return nil
+}
+
+func TestUnary_PropagatesTraceAndRequestID(t *testing.T) {
+	client, health, recorder := setup(t)
+
+	var header metadata.MD
+	ctx := ContextWithRequestID(context.Background(), "req-1")
+	_, err := client.Check(ctx, &healthpb.HealthCheckRequest{}, grpc.Header(&header))
+	require.NoError(t, err)
+
+	assert.Equal(t, "req-1", health.requestID)
+	assert.Equal(t, []string{"req-1"}, header.Get(RequestIDKey))
+
+	clientSpan, serverSpan := spansByKind(t, recorder)
+	assert.Equal(t, "grpc.health.v1.Health/Check", serverSpan.Name())
+	assert.Equal(t, clientSpan.SpanContext().TraceID(), serverSpan.SpanContext().TraceID())
+	assert.Equal(t, clientSpan.SpanContext().SpanID(), serverSpan.Parent().SpanID())
+	assert.Equal(t, "grpc", attr(serverSpan, "rpc.system"))
+	assert.Equal(t, "grpc.health.v1.Health", attr(serverSpan, "rpc.service"))
+	assert.Equal(t, "Check", attr(serverSpan, "rpc.method"))
+	assert.Equal(t, int64(codes.OK), attr(serverSpan, "rpc.grpc.status_code"))
+}
+
+func TestUnary_StatusCodes(t *testing.T) {
+	client, _, recorder := setup(t)
+
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	clientSpan, serverSpan := spansByKind(t, recorder)
+	assert.Equal(t, otelcodes.Error, clientSpan.Status().Code, "clients flag every non OK code")
+	assert.Equal(t, otelcodes.Unset, serverSpan.Status().Code, "NotFound is not a server error")
+	assert.Equal(t, int64(codes.NotFound), attr(serverSpan, "rpc.grpc.status_code"))
+}
+
+func TestUnary_RecoversPanic(t *testing.T) {
+	lgr := &errorLogger{errors: make(map[string][]interface{})}
+	client, _, recorder := setup(t, func(c *Config) { c.Logger = lgr })
+
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "panic"})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	_, serverSpan := spansByKind(t, recorder)
+	assert.Equal(t, otelcodes.Error, serverSpan.Status().Code)
+	require.Len(t, serverSpan.Events(), 1, "the panic is recorded once")
+	assert.Equal(t, "exception", serverSpan.Events()[0].Name)
+	stack, _ := eventAttr(serverSpan.Events()[0], "exception.stacktrace")
+	assert.NotEmpty(t, stack, "the recorded exception is the panic")
+
+	keyvals := lgr.get("gRPC handler panic")
+	require.NotEmpty(t, keyvals)
+	assert.Equal(t, []interface{}{"error", "boom", "method", "/grpc.health.v1.Health/Check", "stack", stack}, keyvals,
+		"the log carries the stack recorded on the span")
+}
+
+func eventAttr(event sdktrace.Event, key string) (string, bool) {
+	for _, attr := range event.Attributes {
+		if string(attr.Key) == key {
+			return attr.Value.AsString(), true
+		}
+	}
+	return "", false
+}
+
+func TestStream_EndsSpansOnEOF(t *testing.T) {
+	client, health, recorder := setup(t)
+
+	stream, err := client.Watch(ContextWithRequestID(context.Background(), "req-2"), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	var received int
+	for {
+		_, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		received++
+	}
+	assert.Equal(t, 2, received)
+	assert.Equal(t, "req-2", health.requestID)
+
+	clientSpan, serverSpan := spansByKind(t, recorder)
+	assert.Equal(t, "grpc.health.v1.Health/Watch", clientSpan.Name())
+	assert.Equal(t, clientSpan.SpanContext().SpanID(), serverSpan.Parent().SpanID())
+	assert.Equal(t, otelcodes.Unset, clientSpan.Status().Code)
+}
+
+func TestConfig_NilSDKTracerProviderDisablesTracing(t *testing.T) {
+	// what a failed telemetry.InitTracer leaves behind
+	var provider *sdktrace.TracerProvider
+	config := DefaultConfig()
+	config.TracerProvider = provider
+
+	_, span := config.tracer().Start(context.Background(), "probe")
+	assert.False(t, span.IsRecording())
+}
@@ -0,0 +1,124 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor returns a unary server interceptor that adds OpenTelemetry tracing
+func UnaryServerInterceptor(config *Config) grpc.UnaryServerInterceptor {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		if config.shouldSkipMethod(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		ctx, c := startServerCall(ctx, config, info.FullMethod)
+		defer func() {
+			if r := recover(); r != nil {
+				err = c.recovered(ctx, r)
+			}
+			c.end(ctx, err)
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a stream server interceptor that adds OpenTelemetry tracing
+func StreamServerInterceptor(config *Config) grpc.StreamServerInterceptor {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		if config.shouldSkipMethod(info.FullMethod) {
+			return handler(srv, ss)
+		}
+
+		ctx, c := startServerCall(ss.Context(), config, info.FullMethod)
+		defer func() {
+			if r := recover(); r != nil {
+				err = c.recovered(ctx, r)
+			}
+			c.end(ctx, err)
+		}()
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// serverStream replaces the context of a grpc.ServerStream with the traced one
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context implements grpc.ServerStream
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// startServerCall extracts the trace context and request ID from the incoming metadata and starts the server span
+func startServerCall(ctx context.Context, config *Config, fullMethod string) (context.Context, *call) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = config.propagator().Extract(ctx, metadataCarrier(md))
+
+	var requestID string
+	if config.GenerateRequestID {
+		requestID = metadataCarrier(md).Get(RequestIDKey)
+		if requestID == "" {
+			requestID = uuid.New().String()
+		}
+		ctx = ContextWithRequestID(ctx, requestID)
+		// the header is sent with the first response, failing only when it was already sent
+		_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDKey, requestID))
+	}
+
+	var attrs []attribute.KeyValue
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		attrs = append(attrs, attribute.String("net.peer.addr", p.Addr.String()))
+	}
+	if requestID != "" {
+		attrs = append(attrs, attribute.String("rpc.request_id", requestID))
+	}
+
+	ctx, span := startSpan(ctx, config, fullMethod, trace.SpanKindServer, attrs...)
+	return ctx, &call{
+		config:     config,
+		span:       span,
+		fullMethod: fullMethod,
+		requestID:  requestID,
+		start:      time.Now(),
+		server:     true,
+	}
+}
+
+// recovered logs a handler panic and converts it to an Internal error, a panicking
+// gRPC handler would otherwise crash the whole server
+func (c *call) recovered(ctx context.Context, r any) error {
+	c.panicked = true
+	stack := string(debug.Stack())
+	c.span.RecordError(fmt.Errorf("panic: %v", r), trace.WithAttributes(
+		attribute.String("exception.stacktrace", stack),
+	))
+	if c.config.Logger != nil {
+		c.config.Logger.ErrorContext(ctx, "gRPC handler panic",
+			"error", r,
+			"method", c.fullMethod,
+			"stack", stack)
+	}
+	return status.Error(codes.Internal, "internal error")
+}
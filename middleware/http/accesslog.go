@@ -0,0 +1,299 @@
+package http
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AccessLogFormat is an Apache mod_log_config style template, or AccessLogJSON
+type AccessLogFormat string
+
+const (
+	// AccessLogCommon is the NCSA Common Log Format
+	AccessLogCommon AccessLogFormat = `%h %l %u %t "%r" %>s %b`
+	// AccessLogCombined is the NCSA Combined Log Format, Common plus referer and user agent
+	AccessLogCombined AccessLogFormat = `%h %l %u %t "%r" %>s %b "%{Referer}i" "%{User-Agent}i"`
+	// AccessLogJSON writes one JSON object per request
+	AccessLogJSON AccessLogFormat = "json"
+)
+
+// AccessLogConfig writes one line per request to its own sink, independently of Logger.
+//
+// Templates support the tokens %h (client IP, see Config.TrustedProxies), %l (always "-"), %u (basic auth user),
+// %t (request time), %r (request line), %s and %>s (status), %b (bytes, "-" when empty),
+// %B (bytes), %D (duration in microseconds), %T (duration in seconds), %m (method),
+// %U (path), %q (query string), %H (protocol), %{Name}i (request header),
+// %{Name}o (response header) and %%. Unknown tokens are written as is.
+type AccessLogConfig struct {
+	// Writer receives the lines, writes are serialized
+	Writer io.Writer
+
+	// Format is AccessLogCommon, AccessLogCombined, AccessLogJSON or a custom template
+	// (defaults to AccessLogCombined)
+	Format AccessLogFormat
+}
+
+// accessLogTimeFormat is the Apache %t layout
+const accessLogTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// accessLog renders and writes the access log lines of a middleware
+type accessLog struct {
+	mu     sync.Mutex
+	w      io.Writer
+	json   bool
+	tokens []accessLogToken
+}
+
+// accessLogToken appends a part of the line for a request
+type accessLogToken func(buf []byte, e *accessLogEntry) []byte
+
+// accessLogEntry is a completed request
+type accessLogEntry struct {
+	req       *requestInfo
+	start     time.Time
+	duration  time.Duration
+	status    int
+	bytes     int
+	header    func(key string) []string
+	clientIP  string
+	requestID string
+	traceID   string
+}
+
+// newAccessLog compiles the configured format, it returns nil when access logs are disabled
+func newAccessLog(config *AccessLogConfig) *accessLog {
+	if config == nil || config.Writer == nil {
+		return nil
+	}
+	format := config.Format
+	if format == "" {
+		format = AccessLogCombined
+	}
+	if format == AccessLogJSON {
+		return &accessLog{w: config.Writer, json: true}
+	}
+	return &accessLog{w: config.Writer, tokens: parseAccessLogFormat(string(format))}
+}
+
+// write renders the entry and writes it as a line, write errors are ignored
+func (l *accessLog) write(e *accessLogEntry) {
+	if l == nil {
+		return
+	}
+	var line []byte
+	if l.json {
+		line = e.appendJSON(nil)
+	} else {
+		for _, token := range l.tokens {
+			line = token(line, e)
+		}
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = l.w.Write(line)
+}
+
+// parseAccessLogFormat compiles a template into tokens
+func parseAccessLogFormat(format string) []accessLogToken {
+	var tokens []accessLogToken
+	literal := func(s string) {
+		if s != "" {
+			tokens = append(tokens, func(buf []byte, _ *accessLogEntry) []byte { return append(buf, s...) })
+		}
+	}
+
+	for {
+		i := strings.IndexByte(format, '%')
+		if i < 0 || i == len(format)-1 {
+			literal(format)
+			return tokens
+		}
+		literal(format[:i])
+		format = format[i+1:]
+
+		// %{Name}i and %{Name}o
+		if format[0] == '{' {
+			end := strings.IndexByte(format, '}')
+			if end < 0 || end == len(format)-1 {
+				literal("%" + format)
+				return tokens
+			}
+			name, kind := format[1:end], format[end+1]
+			switch kind {
+			case 'i':
+				tokens = append(tokens, func(buf []byte, e *accessLogEntry) []byte {
+					return appendEscaped(buf, e.req.header.Get(name))
+				})
+			case 'o':
+				tokens = append(tokens, func(buf []byte, e *accessLogEntry) []byte {
+					return appendEscaped(buf, e.responseHeader(name))
+				})
+			default:
+				literal("%" + format[:end+2])
+			}
+			format = format[end+2:]
+			continue
+		}
+
+		// %>s is the final status, the only status the middleware sees
+		if strings.HasPrefix(format, ">s") {
+			format = format[1:]
+		}
+		if token, ok := accessLogDirectives[format[0]]; ok {
+			tokens = append(tokens, token)
+		} else {
+			literal("%" + format[:1])
+		}
+		format = format[1:]
+	}
+}
+
+// accessLogDirectives are the single letter tokens
+var accessLogDirectives = map[byte]accessLogToken{
+	'%': func(buf []byte, _ *accessLogEntry) []byte { return append(buf, '%') },
+	'h': func(buf []byte, e *accessLogEntry) []byte { return appendOrDash(buf, e.remoteHost()) },
+	'l': func(buf []byte, _ *accessLogEntry) []byte { return append(buf, '-') },
+	'u': func(buf []byte, e *accessLogEntry) []byte { return appendEscaped(buf, e.remoteUser()) },
+	't': func(buf []byte, e *accessLogEntry) []byte {
+		buf = append(buf, '[')
+		buf = e.start.AppendFormat(buf, accessLogTimeFormat)
+		return append(buf, ']')
+	},
+	'r': func(buf []byte, e *accessLogEntry) []byte {
+		return appendEscaped(buf, e.req.method+" "+e.req.target+" "+e.req.proto)
+	},
+	's': func(buf []byte, e *accessLogEntry) []byte { return strconv.AppendInt(buf, int64(e.status), 10) },
+	'b': func(buf []byte, e *accessLogEntry) []byte {
+		if e.bytes == 0 {
+			return append(buf, '-')
+		}
+		return strconv.AppendInt(buf, int64(e.bytes), 10)
+	},
+	'B': func(buf []byte, e *accessLogEntry) []byte { return strconv.AppendInt(buf, int64(e.bytes), 10) },
+	'D': func(buf []byte, e *accessLogEntry) []byte {
+		return strconv.AppendInt(buf, e.duration.Microseconds(), 10)
+	},
+	'T': func(buf []byte, e *accessLogEntry) []byte {
+		return strconv.AppendInt(buf, int64(e.duration/time.Second), 10)
+	},
+	'm': func(buf []byte, e *accessLogEntry) []byte { return append(buf, e.req.method...) },
+	'U': func(buf []byte, e *accessLogEntry) []byte { return appendEscaped(buf, e.req.path) },
+	'q': func(buf []byte, e *accessLogEntry) []byte { return append(buf, e.query()...) },
+	'H': func(buf []byte, e *accessLogEntry) []byte { return append(buf, e.req.proto...) },
+}
+
+func appendOrDash(buf []byte, s string) []byte {
+	if s == "" {
+		return append(buf, '-')
+	}
+	return append(buf, s...)
+}
+
+// appendEscaped appends client controlled values like Apache does: quotes and backslashes are
+// escaped and control characters written as \xhh, so a value cannot forge fields or lines
+func appendEscaped(buf []byte, s string) []byte {
+	if s == "" {
+		return append(buf, '-')
+	}
+	const hex = "0123456789abcdef"
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			buf = append(buf, '\\', c)
+		case c < 0x20 || c == 0x7f:
+			buf = append(buf, '\\', 'x', hex[c>>4], hex[c&0xf])
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return buf
+}
+
+// remoteHost returns the client IP resolved from trusted proxies, or the peer address
+// without its port
+func (e *accessLogEntry) remoteHost() string {
+	if e.clientIP != "" {
+		return e.clientIP
+	}
+	if host, _, err := net.SplitHostPort(e.req.remoteAddr); err == nil {
+		return host
+	}
+	return e.req.remoteAddr
+}
+
+// remoteUser returns the basic auth user name, the password is never decoded into the line
+func (e *accessLogEntry) remoteUser() string {
+	const prefix = "Basic "
+	auth := e.req.header.Get("Authorization")
+	if len(auth) < len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return ""
+	}
+	decoded, err := base64.StdEncoding.DecodeString(auth[len(prefix):])
+	if err != nil {
+		return ""
+	}
+	user, _, _ := strings.Cut(string(decoded), ":")
+	return user
+}
+
+// query returns the query string with its leading "?", or ""
+func (e *accessLogEntry) query() string {
+	if i := strings.IndexByte(e.req.target, '?'); i >= 0 {
+		return e.req.target[i:]
+	}
+	return ""
+}
+
+func (e *accessLogEntry) responseHeader(name string) string {
+	if e.header == nil {
+		return ""
+	}
+	if values := e.header(name); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// accessLogJSON is the AccessLogJSON line, fields are in declaration order
+type accessLogJSON struct {
+	Time       string `json:"time"`
+	RemoteAddr string `json:"remote_addr"`
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	Query      string `json:"query,omitempty"`
+	Protocol   string `json:"protocol"`
+	Status     int    `json:"status"`
+	Bytes      int    `json:"bytes"`
+	DurationUs int64  `json:"duration_us"`
+	Referer    string `json:"referer,omitempty"`
+	UserAgent  string `json:"user_agent,omitempty"`
+	RequestID  string `json:"request_id,omitempty"`
+	TraceID    string `json:"trace_id,omitempty"`
+}
+
+func (e *accessLogEntry) appendJSON(buf []byte) []byte {
+	line, _ := json.Marshal(accessLogJSON{
+		Time:       e.start.Format(time.RFC3339Nano),
+		RemoteAddr: e.remoteHost(),
+		Method:     e.req.method,
+		Path:       e.req.path,
+		Query:      strings.TrimPrefix(e.query(), "?"),
+		Protocol:   e.req.proto,
+		Status:     e.status,
+		Bytes:      e.bytes,
+		DurationUs: e.duration.Microseconds(),
+		Referer:    e.req.header.Get("Referer"),
+		UserAgent:  e.req.userAgent,
+		RequestID:  e.requestID,
+		TraceID:    e.traceID,
+	})
+	return append(buf, line...)
+}
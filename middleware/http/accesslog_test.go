@@ -0,0 +1,122 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func testAccessLogEntry() *accessLogEntry {
+	header := http.Header{}
+	header.Set("Referer", "https://example.com/")
+	header.Set("User-Agent", "curl/8.0")
+	header.Set("X-Request-ID", "req-1")
+	header.Set("Authorization", "Basic amFuZTpzM2NyZXQ=") // jane:s3cret
+	responseHeader := http.Header{}
+	responseHeader.Set("Content-Type", "application/json")
+
+	return &accessLogEntry{
+		req: &requestInfo{
+			method:     http.MethodGet,
+			path:       "/users/42",
+			proto:      "HTTP/1.1",
+			target:     "/users/42?q=1",
+			userAgent:  "curl/8.0",
+			remoteAddr: "192.0.2.1:1234",
+			header:     propagation.HeaderCarrier(header),
+		},
+		start:     time.Date(2024, time.March, 5, 14, 7, 9, 0, time.FixedZone("", 3600)),
+		duration:  1500 * time.Microsecond,
+		status:    http.StatusOK,
+		bytes:     512,
+		header:    responseHeader.Values,
+		requestID: "req-1",
+		traceID:   "4bf92f3577b34da6a3ce929d0e0e4736",
+	}
+}
+
+func writeAccessLog(format AccessLogFormat, e *accessLogEntry) string {
+	var buf bytes.Buffer
+	newAccessLog(&AccessLogConfig{Writer: &buf, Format: format}).write(e)
+	return buf.String()
+}
+
+func TestAccessLog_Formats(t *testing.T) {
+	tests := []struct {
+		name   string
+		format AccessLogFormat
+		want   string
+	}{
+		{
+			name:   "common",
+			format: AccessLogCommon,
+			want:   `192.0.2.1 - jane [05/Mar/2024:14:07:09 +0100] "GET /users/42?q=1 HTTP/1.1" 200 512` + "\n",
+		},
+		{
+			name:   "combined",
+			format: AccessLogCombined,
+			want: `192.0.2.1 - jane [05/Mar/2024:14:07:09 +0100] "GET /users/42?q=1 HTTP/1.1" 200 512 ` +
+				`"https://example.com/" "curl/8.0"` + "\n",
+		},
+		{
+			name:   "custom",
+			format: `%{X-Request-ID}i %D %s %m %U%q %H %{Content-Type}o %{Missing}i %T %B 100%%`,
+			want:   "req-1 1500 200 GET /users/42?q=1 HTTP/1.1 application/json - 0 512 100%\n",
+		},
+		{
+			name:   "unknown tokens",
+			format: `%z %{X}z %`,
+			want:   "%z %{X}z %\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, writeAccessLog(tt.format, testAccessLogEntry()))
+		})
+	}
+}
+
+func TestAccessLog_JSON(t *testing.T) {
+	line := writeAccessLog(AccessLogJSON, testAccessLogEntry())
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal([]byte(line), &got))
+	assert.Equal(t, map[string]any{
+		"time":        "2024-03-05T14:07:09+01:00",
+		"remote_addr": "192.0.2.1",
+		"method":      "GET",
+		"path":        "/users/42",
+		"query":       "q=1",
+		"protocol":    "HTTP/1.1",
+		"status":      float64(200),
+		"bytes":       float64(512),
+		"duration_us": float64(1500),
+		"referer":     "https://example.com/",
+		"user_agent":  "curl/8.0",
+		"request_id":  "req-1",
+		"trace_id":    "4bf92f3577b34da6a3ce929d0e0e4736",
+	}, got)
+}
+
+func TestAccessLog_EscapesClientValues(t *testing.T) {
+	e := testAccessLogEntry()
+	e.req.header.Set("User-Agent", "evil\" 500 -\n127.0.0.1")
+	e.bytes = 0
+
+	assert.Equal(t,
+		`192.0.2.1 - jane [05/Mar/2024:14:07:09 +0100] "GET /users/42?q=1 HTTP/1.1" 200 - `+
+			`"https://example.com/" "evil\" 500 -\x0a127.0.0.1"`+"\n",
+		writeAccessLog(AccessLogCombined, e))
+}
+
+func TestAccessLog_Disabled(t *testing.T) {
+	assert.Nil(t, newAccessLog(nil))
+	assert.Nil(t, newAccessLog(&AccessLogConfig{Format: AccessLogJSON}))
+	assert.NotPanics(t, func() { newAccessLog(nil).write(testAccessLogEntry()) })
+}
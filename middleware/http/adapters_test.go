@@ -0,0 +1,96 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-chi/chi/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	obserrors "github.com/ubin/go-observability/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestGinMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config, recorder := newTestConfig(t)
+
+	r := gin.New()
+	r.Use(GinMiddleware(config))
+	r.GET("/users/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, "user")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, w.Header().Get(RequestIDHeader))
+	name, route := spanRoute(t, recorder)
+	assert.Equal(t, "GET /users/:id", name)
+	assert.Equal(t, "/users/:id", route)
+	assert.Equal(t, recorder.Ended()[0].SpanContext().TraceID().String(), w.Header().Get(TraceIDHeader))
+}
+
+func TestGinMiddleware_RecordsErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config, recorder := newTestConfig(t)
+
+	r := gin.New()
+	r.Use(GinMiddleware(config))
+	r.GET("/fail", func(c *gin.Context) {
+		_ = c.AbortWithError(http.StatusServiceUnavailable, errors.New("database down"))
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	require.Len(t, spans[0].Events(), 1, "the handler error should be recorded")
+}
+
+func TestEchoMiddleware(t *testing.T) {
+	config, recorder := newTestConfig(t)
+
+	e := echo.New()
+	e.Use(EchoMiddleware(config))
+	e.GET("/users/:id", func(c echo.Context) error {
+		return obserrors.New("database down").WithKind(obserrors.KindDependency)
+	})
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+	assert.NotContains(t, w.Body.String(), "database down", "internal messages must not leak")
+	name, route := spanRoute(t, recorder)
+	assert.Equal(t, "GET /users/:id", name)
+	assert.Equal(t, "/users/:id", route)
+	span := recorder.Ended()[0]
+	assert.Equal(t, codes.Error, span.Status().Code)
+	require.Len(t, span.Events(), 1)
+	assert.Contains(t, span.Events()[0].Attributes, attribute.String("error.kind", "dependency"))
+}
+
+func TestChiMiddleware(t *testing.T) {
+	config, recorder := newTestConfig(t)
+
+	r := chi.NewRouter()
+	r.Use(ChiMiddleware(config))
+	r.Get("/users/{id}", ok)
+
+	serve(t, r, "/users/42")
+
+	name, route := spanRoute(t, recorder)
+	assert.Equal(t, "GET /users/{id}", name)
+	assert.Equal(t, "/users/{id}", route)
+	assert.Nil(t, config.RouteResolver, "the caller's config must not be modified")
+}
@@ -0,0 +1,128 @@
+package http
+
+import (
+	"bytes"
+	"mime"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// DefaultBodyCaptureSize is the default number of body bytes recorded on spans
+const DefaultBodyCaptureSize = 4096
+
+// DefaultBodyContentTypes are the media types captured when BodyCaptureConfig.ContentTypes is empty
+var DefaultBodyContentTypes = []string{
+	"application/json",
+	"application/xml",
+	"application/x-www-form-urlencoded",
+	"text/",
+}
+
+// BodyCaptureConfig enables recording request and response bodies on spans as
+// http.request.body and http.response.body, truncated to MaxSize bytes
+type BodyCaptureConfig struct {
+	// Request records the request body, as read by the handler
+	Request bool
+
+	// Response records the response body written by the handler
+	Response bool
+
+	// MaxSize bounds the recorded bytes per body (defaults to DefaultBodyCaptureSize)
+	MaxSize int
+
+	// ContentTypes are the media types recorded, matched by prefix, e.g. "application/json"
+	// or "text/" (defaults to DefaultBodyContentTypes)
+	ContentTypes []string
+
+	// Redact rewrites a captured body before it is recorded, e.g. RedactJSONFields("password")
+	Redact func(body []byte) []byte
+}
+
+// maxSize returns the number of bytes captured per body
+func (c *BodyCaptureConfig) maxSize() int {
+	if c.MaxSize > 0 {
+		return c.MaxSize
+	}
+	return DefaultBodyCaptureSize
+}
+
+// captures reports whether bodies of contentType are recorded
+func (c *BodyCaptureConfig) captures(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	contentTypes := c.ContentTypes
+	if len(contentTypes) == 0 {
+		contentTypes = DefaultBodyContentTypes
+	}
+	for _, prefix := range contentTypes {
+		if strings.HasPrefix(mediaType, strings.ToLower(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// attributes returns the span attributes of a captured body, redacted when configured
+func (c *BodyCaptureConfig) attributes(key string, body *capturedBody) []attribute.KeyValue {
+	data := body.buf.Bytes()
+	if c.Redact != nil {
+		data = c.Redact(data)
+	}
+	attrs := []attribute.KeyValue{attribute.String(key, string(data))}
+	if body.truncated {
+		attrs = append(attrs, attribute.Bool(key+".truncated", true))
+	}
+	return attrs
+}
+
+// capturedBody is an io.Writer keeping the first max bytes written to it
+type capturedBody struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func newCapturedBody(max int) *capturedBody {
+	return &capturedBody{max: max}
+}
+
+// Write implements io.Writer, it never fails so teeing into it cannot break the response
+func (b *capturedBody) Write(p []byte) (int, error) {
+	if remaining := b.max - b.buf.Len(); remaining < len(p) {
+		b.truncated = true
+		b.buf.Write(p[:max(remaining, 0)])
+	} else {
+		b.buf.Write(p)
+	}
+	return len(p), nil
+}
+
+// RedactJSONFields returns a BodyCaptureConfig.Redact func replacing the values of the given
+// JSON fields with "[Filtered]", at any depth. Field names are matched case-insensitively.
+func RedactJSONFields(fields ...string) func(body []byte) []byte {
+	quoted := make([]string, len(fields))
+	for i, field := range fields {
+		quoted[i] = regexp.QuoteMeta(field)
+	}
+	// a string value, with escaped quotes, or a scalar up to the next delimiter
+	re := regexp.MustCompile(`(?i)("(?:` + strings.Join(quoted, "|") + `)"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,}\]\s]+)`)
+	return func(body []byte) []byte {
+		return re.ReplaceAll(body, []byte(`${1}"[Filtered]"`))
+	}
+}
+
+// headerAttributes returns the allow-listed headers as prefix.<lowercased name> attributes,
+// headers absent from the message are omitted
+func headerAttributes(prefix string, names []string, values func(key string) []string) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	for _, name := range names {
+		if v := values(name); len(v) > 0 {
+			attrs = append(attrs, attribute.StringSlice(prefix+strings.ToLower(name), v))
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,18 @@
+package http
+
+import (
+	"net/http"
+)
+
+// ChiMiddleware returns Middleware with ChiRoute as the default route resolver.
+// Register it with the chi router's Use so the route pattern is available.
+func ChiMiddleware(config *Config) func(http.Handler) http.Handler {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	chiConfig := *config
+	if chiConfig.RouteResolver == nil {
+		chiConfig.RouteResolver = ChiRoute
+	}
+	return Middleware(&chiConfig)
+}
@@ -0,0 +1,134 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// DefaultClientIPHeaders are the headers read from trusted proxies when ClientIPHeaders is empty,
+// in order of precedence
+var DefaultClientIPHeaders = []string{"Forwarded", "X-Forwarded-For", "X-Real-IP"}
+
+// ParseTrustedProxies parses IP addresses and CIDRs, e.g. "10.0.0.0/8" or "192.0.2.10",
+// into Config.TrustedProxies
+func ParseTrustedProxies(proxies ...string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			addr, err := netip.ParseAddr(proxy)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+			}
+			addr = addr.Unmap()
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// ClientIPFromContext returns the client IP resolved by the middleware, or "" outside of it
+func ClientIPFromContext(ctx context.Context) string {
+	if f := inflightFromContext(ctx); f != nil {
+		return f.clientIP
+	}
+	return ""
+}
+
+// clientIP returns the address of the client. Forwarding headers are only read when the peer
+// is a trusted proxy: the first header of ClientIPHeaders present is walked from the nearest
+// hop, skipping trusted proxies, and the first untrusted address is the client. A malformed
+// hop stops the walk at the last trusted one.
+func (c *Config) clientIP(req requestInfo) string {
+	peer, ok := parseIP(req.remoteAddr)
+	if !ok {
+		return req.remoteAddr
+	}
+	if !c.trusted(peer) {
+		return peer.String()
+	}
+
+	headers := c.ClientIPHeaders
+	if len(headers) == 0 {
+		headers = DefaultClientIPHeaders
+	}
+	for _, name := range headers {
+		hops := forwardedHops(name, req.headerValues(name))
+		if len(hops) == 0 {
+			continue
+		}
+		client := peer
+		for i := len(hops) - 1; i >= 0; i-- {
+			addr, ok := parseIP(hops[i])
+			if !ok {
+				break
+			}
+			client = addr
+			if !c.trusted(addr) {
+				break
+			}
+		}
+		return client.String()
+	}
+	return peer.String()
+}
+
+// trusted reports whether addr is in TrustedProxies
+func (c *Config) trusted(addr netip.Addr) bool {
+	for _, prefix := range c.TrustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedHops returns the addresses listed by a forwarding header, the client first
+func forwardedHops(name string, values []string) []string {
+	var hops []string
+	for _, value := range values {
+		for _, hop := range strings.Split(value, ",") {
+			if strings.EqualFold(name, "Forwarded") {
+				hop = forwardedFor(hop)
+			}
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	return hops
+}
+
+// forwardedFor returns the for parameter of a RFC 7239 Forwarded element,
+// e.g. `for="[2001:db8::1]:4711";proto=https`
+func forwardedFor(element string) string {
+	for _, pair := range strings.Split(element, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if ok && strings.EqualFold(key, "for") {
+			return strings.Trim(value, `"`)
+		}
+	}
+	// an element without for is a malformed hop, it must not be skipped
+	return "-"
+}
+
+// parseIP parses an address with an optional port, e.g. "192.0.2.1", "192.0.2.1:1234",
+// "2001:db8::1" or "[2001:db8::1]:4711"
+func parseIP(s string) (netip.Addr, bool) {
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	addr, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(s, "["), "]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap().WithZone(""), true
+}
@@ -0,0 +1,127 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted, err := ParseTrustedProxies("10.0.0.0/8", "2001:db8:ffff::/48", "192.0.2.10")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		clientIP   []string
+		want       string
+	}{
+		{
+			name:       "untrusted peer ignores headers",
+			remoteAddr: "203.0.113.7:4711",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.9"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "trusted peer without headers",
+			remoteAddr: "10.0.0.1:4711",
+			want:       "10.0.0.1",
+		},
+		{
+			name:       "x-forwarded-for skips trusted hops",
+			remoteAddr: "10.0.0.1:4711",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.9, 203.0.113.7, 10.0.0.2"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "forwarded takes precedence",
+			remoteAddr: "10.0.0.1:4711",
+			headers: map[string]string{
+				"Forwarded":       `for=198.51.100.9;proto=https, for="[2001:db8:ffff::1]:4711"`,
+				"X-Forwarded-For": "203.0.113.7",
+			},
+			want: "198.51.100.9",
+		},
+		{
+			name:       "forwarded ipv6",
+			remoteAddr: "[2001:db8:ffff::2]:443",
+			headers:    map[string]string{"Forwarded": `for="[2001:db8::1]:4711"`},
+			want:       "2001:db8::1",
+		},
+		{
+			name:       "x-real-ip",
+			remoteAddr: "192.0.2.10:4711",
+			headers:    map[string]string{"X-Real-IP": "203.0.113.7"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "every hop trusted",
+			remoteAddr: "10.0.0.1:4711",
+			headers:    map[string]string{"X-Forwarded-For": "10.0.0.3, 10.0.0.2"},
+			want:       "10.0.0.3",
+		},
+		{
+			name:       "malformed hop stops at the last trusted one",
+			remoteAddr: "10.0.0.1:4711",
+			headers:    map[string]string{"X-Forwarded-For": "203.0.113.7, unknown, 10.0.0.2"},
+			want:       "10.0.0.2",
+		},
+		{
+			name:       "forwarded element without for",
+			remoteAddr: "10.0.0.1:4711",
+			headers:    map[string]string{"Forwarded": "for=203.0.113.7, proto=https"},
+			want:       "10.0.0.1",
+		},
+		{
+			name:       "custom headers",
+			remoteAddr: "10.0.0.1:4711",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.9", "CF-Connecting-IP": "203.0.113.7"},
+			clientIP:   []string{"CF-Connecting-IP"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "ipv4 mapped peer",
+			remoteAddr: "[::ffff:10.0.0.1]:4711",
+			headers:    map[string]string{"X-Forwarded-For": "203.0.113.7"},
+			want:       "203.0.113.7",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{TrustedProxies: trusted, ClientIPHeaders: tt.clientIP}
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for key, value := range tt.headers {
+				r.Header.Set(key, value)
+			}
+			assert.Equal(t, tt.want, config.clientIP(httpRequestInfo(r, nil)))
+		})
+	}
+}
+
+func TestParseTrustedProxies_Invalid(t *testing.T) {
+	_, err := ParseTrustedProxies("10.0.0.0/8", "10.0.0.0/33")
+	assert.ErrorContains(t, err, `invalid trusted proxy "10.0.0.0/33"`)
+	_, err = ParseTrustedProxies("proxy.internal")
+	assert.Error(t, err)
+}
+
+func TestClientIPFromContext(t *testing.T) {
+	config := DefaultConfig()
+	config.TrustedProxies, _ = ParseTrustedProxies("192.0.2.1")
+
+	var clientIP string
+	handler := Middleware(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientIP = ClientIPFromContext(r.Context())
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Real-IP", "203.0.113.7")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Equal(t, "203.0.113.7", clientIP)
+	assert.Empty(t, ClientIPFromContext(r.Context()), "outside of the middleware")
+}
@@ -1,32 +1,109 @@
 package http
 
 import (
+	"net/http"
+	"net/netip"
+
+	"github.com/getsentry/sentry-go"
 	"github.com/ubin/go-observability/logger"
-	"go.opentelemetry.io/otel/sdk/trace"
+	"github.com/ubin/go-observability/middleware/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Config holds configuration for HTTP tracing middleware
 type Config struct {
-	// TracerProvider is the OpenTelemetry tracer provider
-	// If nil, tracing will be skipped
-	TracerProvider *trace.TracerProvider
+	// TracerProvider is the OpenTelemetry tracer provider, e.g. from telemetry.InitTracer
+	// or the reloadable one from reload.Manager
+	// If nil, including a nil *sdktrace.TracerProvider, tracing will be skipped
+	TracerProvider trace.TracerProvider
+
+	// MeterProvider records the HTTP server metrics (request duration, active requests,
+	// request and response body sizes)
+	// If nil, metrics are not recorded
+	MeterProvider metric.MeterProvider
 
 	// Logger is used for logging HTTP requests
 	// If nil, logging will be skipped
 	Logger logger.ContextLogger
 
+	// Propagator extracts the trace context from incoming headers
+	// If nil, the global OpenTelemetry propagator is used
+	Propagator propagation.TextMapPropagator
+
 	// ServiceName is the name of the service for tracing (defaults to "http-server")
 	ServiceName string
 
+	// TrustedProxies are the proxies, e.g. load balancers, whose forwarding headers are trusted
+	// to resolve the client IP recorded as client.address, see ParseTrustedProxies.
+	// If empty, the client IP is the peer address.
+	TrustedProxies []netip.Prefix
+
+	// ClientIPHeaders are the forwarding headers read from trusted proxies, the first present
+	// wins (defaults to DefaultClientIPHeaders: Forwarded, X-Forwarded-For, X-Real-IP)
+	ClientIPHeaders []string
+
+	// RouteResolver returns the matched route template used for span names and http.route,
+	// e.g. ChiRoute or GorillaRoute. Patterns of Go 1.22+ http.ServeMux are used without it,
+	// unresolved requests fall back to NormalizePath.
+	RouteResolver RouteResolver
+
+	// RequestHeaders and ResponseHeaders are the header names recorded on spans as
+	// http.request.header.<name> and http.response.header.<name> (lowercased)
+	RequestHeaders  []string
+	ResponseHeaders []string
+
+	// BodyCapture records truncated request and response bodies on spans
+	// If nil, bodies are not recorded
+	BodyCapture *BodyCaptureConfig
+
 	// SkipPaths are paths to exclude from tracing (e.g., /health, /metrics)
 	// Useful for reducing noise from health checks
 	SkipPaths []string
 
+	// SkipRules exclude requests by prefix, glob, regex, method, user agent or predicate,
+	// or only disable their logs, see SkipRule
+	SkipRules []SkipRule
+
 	// SkipLogging disables request logging if true
 	SkipLogging bool
 
+	// AccessLog writes Common, Combined, JSON or custom access log lines to its own writer
+	// If nil, no access log is written
+	AccessLog *AccessLogConfig
+
+	// CanonicalLog replaces the received and completed log lines with a single "HTTP request"
+	// line per request, carrying the fields added by handlers with httpobs.Annotate
+	CanonicalLog bool
+
+	// StatusClassifier reports whether a completed request failed: its span status is set to
+	// Error and its canonical log line logged as an error, e.g. to treat 429 as an error on
+	// some routes. Requests the client aborted are recorded with StatusClientClosedRequest
+	// and http.client_aborted.
+	// If nil, DefaultStatusClassifier is used: only 5xx statuses are errors
+	StatusClassifier StatusClassifier
+
+	// RecoverPanics responds 500 to panicking handlers instead of re-panicking once the
+	// panic is recorded. Keep it false when an outer recovery middleware handles panics.
+	RecoverPanics bool
+
+	// TraceHeaders selects the trace context response headers, e.g. to rename or disable
+	// the trace and span ID headers on public endpoints or to set the W3C traceresponse header
+	// If nil, the IDs are set as X-Trace-ID and X-Span-ID
+	TraceHeaders *TraceHeadersConfig
+
+	// ServerTiming sets the Server-Timing header with the total handler time and the phases
+	// recorded with httpobs.RecordTiming before the response headers were written
+	ServerTiming bool
+
 	// GenerateRequestID enables request ID generation and X-Request-ID header
 	GenerateRequestID bool
+
+	// SentryUser extracts the user attached to Sentry events for a request.
+	// Only used when Sentry is initialized, Fiber requests are converted to *http.Request.
+	SentryUser func(r *http.Request) *sentry.User
 }
 
 // DefaultConfig returns a config with sensible defaults
@@ -48,3 +125,19 @@ func (c *Config) shouldSkipPath(path string) bool {
 	}
 	return false
 }
+
+// propagator returns the configured propagator or the global one
+func (c *Config) propagator() propagation.TextMapPropagator {
+	if c.Propagator != nil {
+		return c.Propagator
+	}
+	return otel.GetTextMapPropagator()
+}
+
+// tracerProvider returns the configured tracer provider, or nil when tracing is disabled
+func (c *Config) tracerProvider() trace.TracerProvider {
+	if !tracing.Enabled(c.TracerProvider) {
+		return nil
+	}
+	return c.TracerProvider
+}
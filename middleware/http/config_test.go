@@ -0,0 +1,31 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestConfig_NilSDKTracerProviderDisablesTracing(t *testing.T) {
+	// what a failed telemetry.InitTracer leaves behind
+	var provider *sdktrace.TracerProvider
+	config := DefaultConfig()
+	config.TracerProvider = provider
+
+	server := httptest.NewServer(Middleware(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+	defer server.Close()
+
+	client := &http.Client{Transport: Transport(nil, config)}
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get(TraceIDHeader))
+}
@@ -0,0 +1,527 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-chi/chi/v5"
+	"github.com/gofiber/fiber/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	obserrors "github.com/ubin/go-observability/errors"
+	"github.com/ubin/go-observability/middleware/httpobs"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// conformanceAdapter builds an instrumented app serving the conformance routes:
+// GET /users/{id} annotates user_id, records a 5ms "db" timing and responds 200 "ok", GET /fail reports a dependency error,
+// GET /canceled fails with context.Canceled, GET /panic panics and POST /echo responds with the JSON body it received
+type conformanceAdapter struct {
+	name string
+	// route is the template the framework reports for /users/42
+	route string
+	build func(config *Config) func(r *http.Request) *http.Response
+}
+
+var conformanceAdapters = []conformanceAdapter{
+	{
+		name:  "stdlib",
+		route: "/users/{id}",
+		build: func(config *Config) func(r *http.Request) *http.Response {
+			m := http.NewServeMux()
+			m.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+				httpobs.Annotate(r.Context(), "user_id", r.PathValue("id"))
+				httpobs.RecordTiming(r.Context(), "db", 5*time.Millisecond)
+				writeOK(w, r)
+			})
+			m.HandleFunc("GET /fail", func(w http.ResponseWriter, r *http.Request) {
+				WriteError(w, r, dependencyError())
+			})
+			m.HandleFunc("GET /canceled", func(w http.ResponseWriter, r *http.Request) {
+				WriteError(w, r, context.Canceled)
+			})
+			m.HandleFunc("GET /panic", panicking)
+			m.HandleFunc("POST /echo", echoJSON)
+			return handlerClient(Middleware(config)(m))
+		},
+	},
+	{
+		name:  "chi",
+		route: "/users/{id}",
+		build: func(config *Config) func(r *http.Request) *http.Response {
+			r := chi.NewRouter()
+			r.Use(ChiMiddleware(config))
+			r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+				httpobs.Annotate(r.Context(), "user_id", chi.URLParam(r, "id"))
+				httpobs.RecordTiming(r.Context(), "db", 5*time.Millisecond)
+				writeOK(w, r)
+			})
+			r.Get("/fail", func(w http.ResponseWriter, r *http.Request) {
+				WriteError(w, r, dependencyError())
+			})
+			r.Get("/canceled", func(w http.ResponseWriter, r *http.Request) {
+				WriteError(w, r, context.Canceled)
+			})
+			r.Get("/panic", panicking)
+			r.Post("/echo", echoJSON)
+			return handlerClient(r)
+		},
+	},
+	{
+		name:  "gin",
+		route: "/users/:id",
+		build: func(config *Config) func(r *http.Request) *http.Response {
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(GinMiddleware(config))
+			r.GET("/users/:id", func(c *gin.Context) {
+				httpobs.Annotate(c.Request.Context(), "user_id", c.Param("id"))
+				httpobs.RecordTiming(c.Request.Context(), "db", 5*time.Millisecond)
+				c.String(http.StatusOK, "ok")
+			})
+			r.GET("/fail", func(c *gin.Context) {
+				err := dependencyError()
+				_ = c.Error(err)
+				c.AbortWithStatus(obserrors.HTTPStatus(err))
+			})
+			r.GET("/canceled", func(c *gin.Context) {
+				_ = c.Error(context.Canceled)
+				c.AbortWithStatus(http.StatusInternalServerError)
+			})
+			r.GET("/panic", func(c *gin.Context) {
+				panicking(c.Writer, c.Request)
+			})
+			r.POST("/echo", func(c *gin.Context) {
+				body, _ := io.ReadAll(c.Request.Body)
+				c.Header("X-Partner", "acme")
+				c.Data(http.StatusOK, "application/json", body)
+			})
+			return handlerClient(r)
+		},
+	},
+	{
+		name:  "echo",
+		route: "/users/:id",
+		build: func(config *Config) func(r *http.Request) *http.Response {
+			e := echo.New()
+			e.Use(EchoMiddleware(config))
+			e.GET("/users/:id", func(c echo.Context) error {
+				httpobs.Annotate(c.Request().Context(), "user_id", c.Param("id"))
+				httpobs.RecordTiming(c.Request().Context(), "db", 5*time.Millisecond)
+				return c.String(http.StatusOK, "ok")
+			})
+			e.GET("/fail", func(c echo.Context) error {
+				return dependencyError()
+			})
+			e.GET("/canceled", func(c echo.Context) error {
+				return context.Canceled
+			})
+			e.GET("/panic", func(c echo.Context) error {
+				panicking(c.Response(), c.Request())
+				return nil
+			})
+			e.POST("/echo", func(c echo.Context) error {
+				body, _ := io.ReadAll(c.Request().Body)
+				c.Response().Header().Set("X-Partner", "acme")
+				return c.Blob(http.StatusOK, "application/json", body)
+			})
+			return handlerClient(e)
+		},
+	},
+	{
+		name:  "fiber",
+		route: "/users/:id",
+		build: func(config *Config) func(r *http.Request) *http.Response {
+			app := fiber.New()
+			app.Use(FiberMiddleware(config))
+			app.Get("/users/:id", func(c *fiber.Ctx) error {
+				httpobs.Annotate(c.UserContext(), "user_id", c.Params("id"))
+				httpobs.RecordTiming(c.UserContext(), "db", 5*time.Millisecond)
+				return c.SendString("ok")
+			})
+			app.Get("/fail", func(c *fiber.Ctx) error {
+				return dependencyError()
+			})
+			app.Get("/canceled", func(c *fiber.Ctx) error {
+				return context.Canceled
+			})
+			app.Get("/panic", func(c *fiber.Ctx) error {
+				panicking(nil, nil)
+				return nil
+			})
+			app.Post("/echo", func(c *fiber.Ctx) error {
+				c.Set("X-Partner", "acme")
+				c.Set(fiber.HeaderContentType, "application/json")
+				return c.Send(c.Body())
+			})
+			return func(r *http.Request) *http.Response {
+				resp, err := app.Test(r, -1)
+				if err != nil {
+					panic(err)
+				}
+				return resp
+			}
+		},
+	},
+}
+
+func writeOK(w http.ResponseWriter, r *http.Request) {
+	_, _ = w.Write([]byte("ok"))
+}
+
+func echoJSON(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	w.Header().Set("X-Partner", "acme")
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
+
+func dependencyError() error {
+	return obserrors.New("database down").WithKind(obserrors.KindDependency)
+}
+
+func handlerClient(h http.Handler) func(r *http.Request) *http.Response {
+	return func(r *http.Request) *http.Response {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Result()
+	}
+}
+
+// spanAttrs returns the span attributes by key
+func spanAttrs(span sdktrace.ReadOnlySpan) map[string]attribute.Value {
+	attrs := make(map[string]attribute.Value)
+	for _, attr := range span.Attributes() {
+		attrs[string(attr.Key)] = attr.Value
+	}
+	return attrs
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// logKeys returns the sorted keys of a log entry
+func logKeys(entry logEntry) []string {
+	keys := make(map[string]struct{})
+	for i := 0; i+1 < len(entry.keyvals); i += 2 {
+		keys[entry.keyvals[i].(string)] = struct{}{}
+	}
+	return sortedKeys(keys)
+}
+
+// TestConformance checks every adapter produces the same headers, spans, logs and error
+// semantics, new adapters must be added to conformanceAdapters
+func TestConformance(t *testing.T) {
+	for _, adapter := range conformanceAdapters {
+		t.Run(adapter.name, func(t *testing.T) {
+			setup := func(t *testing.T, opts ...func(*Config)) (func(r *http.Request) *http.Response, *tracetest.SpanRecorder, *recordingLogger) {
+				config, recorder := newTestConfig(t)
+				lgr := &recordingLogger{}
+				config.Logger = lgr
+				config.Propagator = propagation.TraceContext{}
+				config.RecoverPanics = true
+				config.SkipPaths = []string{"/health"}
+				config.SkipRules = []SkipRule{{Prefix: "/quiet/", Mode: SkipLogsOnly}}
+				for _, opt := range opts {
+					opt(config)
+				}
+				return adapter.build(config), recorder, lgr
+			}
+
+			t.Run("success", func(t *testing.T) {
+				do, recorder, lgr := setup(t)
+
+				parent := trace.NewSpanContext(trace.SpanContextConfig{
+					TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+					SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+					TraceFlags: trace.FlagsSampled,
+				})
+				req := httptest.NewRequest(http.MethodGet, "/users/42?q=1", nil)
+				req.Header.Set("traceparent", "00-"+parent.TraceID().String()+"-"+parent.SpanID().String()+"-01")
+				req.Header.Set(RequestIDHeader, "req-1")
+				req.Header.Set("User-Agent", "conformance")
+				resp := do(req)
+
+				body, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, "ok", string(body))
+
+				spans := recorder.Ended()
+				require.Len(t, spans, 1)
+				span := spans[0]
+				assert.Equal(t, "GET "+adapter.route, span.Name())
+				assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+				assert.Equal(t, parent.TraceID(), span.SpanContext().TraceID())
+				assert.Equal(t, parent.SpanID(), span.Parent().SpanID())
+
+				assert.Equal(t, "req-1", resp.Header.Get(RequestIDHeader))
+				assert.Equal(t, span.SpanContext().TraceID().String(), resp.Header.Get(TraceIDHeader))
+				assert.Equal(t, span.SpanContext().SpanID().String(), resp.Header.Get(SpanIDHeader))
+				assert.Empty(t, resp.Header.Get(TraceResponseHeader))
+				assert.Empty(t, resp.Header.Get(ServerTimingHeader))
+
+				attrs := spanAttrs(span)
+				assert.Equal(t, []string{
+					"client.address", "http.host", "http.method", "http.path", "http.remote_addr", "http.request_id",
+					"http.response_size", "http.route", "http.scheme", "http.status_code",
+					"http.target", "http.user_agent",
+				}, sortedKeys(attrs))
+				assert.Equal(t, "GET", attrs["http.method"].AsString())
+				assert.Equal(t, "/users/42", attrs["http.path"].AsString())
+				assert.Equal(t, adapter.route, attrs["http.route"].AsString())
+				assert.Equal(t, "http", attrs["http.scheme"].AsString())
+				assert.Equal(t, "/users/42?q=1", attrs["http.target"].AsString())
+				assert.Equal(t, "example.com", attrs["http.host"].AsString())
+				assert.Equal(t, "conformance", attrs["http.user_agent"].AsString())
+				assert.Equal(t, "req-1", attrs["http.request_id"].AsString())
+				assert.Equal(t, int64(http.StatusOK), attrs["http.status_code"].AsInt64())
+				assert.Equal(t, int64(len("ok")), attrs["http.response_size"].AsInt64())
+				assert.Equal(t, codes.Unset, span.Status().Code)
+
+				require.Equal(t, []string{"HTTP request received", "HTTP request completed"}, lgr.messages("info"))
+				assert.Equal(t, []string{"client_ip", "method", "path", "remote_addr", "request_id", "span_id", "trace_id"},
+					logKeys(lgr.entries[0]))
+				assert.Equal(t, []string{"bytes", "client_ip", "duration_ms", "method", "path", "remote_addr",
+					"request_id", "route", "span_id", "status", "trace_id", "user_id"}, logKeys(lgr.entries[1]))
+			})
+
+			t.Run("error", func(t *testing.T) {
+				do, recorder, _ := setup(t)
+
+				resp := do(httptest.NewRequest(http.MethodGet, "/fail", nil))
+				body, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+				assert.NotContains(t, string(body), "database down", "internal messages must not leak")
+
+				spans := recorder.Ended()
+				require.Len(t, spans, 1)
+				span := spans[0]
+				assert.Equal(t, codes.Error, span.Status().Code)
+				assert.Equal(t, int64(http.StatusBadGateway), spanAttrs(span)["http.status_code"].AsInt64())
+				require.Len(t, span.Events(), 1, "the handler error should be recorded once")
+				assert.Contains(t, span.Events()[0].Attributes, attribute.String("error.kind", "dependency"))
+			})
+
+			t.Run("unmatched route", func(t *testing.T) {
+				reader := sdkmetric.NewManualReader()
+				do, recorder, _ := setup(t, func(c *Config) {
+					c.MeterProvider = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+				})
+
+				resp := do(httptest.NewRequest(http.MethodGet, "/nope/123", nil))
+				assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+				spans := recorder.Ended()
+				require.Len(t, spans, 1)
+				assert.Equal(t, "GET /nope/{id}", spans[0].Name())
+				assert.Equal(t, "/nope/{id}", spanAttrs(spans[0])["http.route"].AsString())
+
+				duration := collect(t, reader)["http.server.request.duration"].(metricdata.Histogram[float64])
+				require.Len(t, duration.DataPoints, 1)
+				_, ok := duration.DataPoints[0].Attributes.Value("http.route")
+				assert.False(t, ok, "unmatched requests have no route in metrics")
+			})
+
+			t.Run("client aborted", func(t *testing.T) {
+				do, recorder, lgr := setup(t)
+
+				do(httptest.NewRequest(http.MethodGet, "/canceled", nil))
+
+				spans := recorder.Ended()
+				require.Len(t, spans, 1)
+				attrs := spanAttrs(spans[0])
+				assert.Equal(t, codes.Unset, spans[0].Status().Code, "the client's doing, not a server error")
+				assert.Empty(t, spans[0].Events())
+				assert.Equal(t, int64(StatusClientClosedRequest), attrs["http.status_code"].AsInt64())
+				assert.True(t, attrs["http.client_aborted"].AsBool())
+				require.Len(t, lgr.entries, 2)
+				assert.Contains(t, logKeys(lgr.entries[1]), "client_aborted")
+			})
+
+			t.Run("status classifier", func(t *testing.T) {
+				var classified []RequestStatus
+				do, recorder, _ := setup(t, func(c *Config) {
+					c.StatusClassifier = func(s RequestStatus) bool {
+						classified = append(classified, s)
+						return s.ClientAborted && s.Route == "/canceled"
+					}
+				})
+
+				do(httptest.NewRequest(http.MethodGet, "/canceled", nil))
+				do(httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+				spans := recorder.Ended()
+				require.Len(t, spans, 2)
+				assert.Equal(t, codes.Error, spans[0].Status().Code)
+				require.Len(t, spans[0].Events(), 1)
+				assert.Equal(t, codes.Unset, spans[1].Status().Code, "a 502 the classifier accepts")
+				require.Len(t, spans[1].Events(), 1, "the handler error is still recorded")
+
+				require.Len(t, classified, 2)
+				assert.Equal(t, RequestStatus{Method: http.MethodGet, Route: "/canceled", Status: StatusClientClosedRequest,
+					Err: context.Canceled, ClientAborted: true}, classified[0])
+				assert.Equal(t, http.StatusBadGateway, classified[1].Status)
+			})
+
+			t.Run("panic", func(t *testing.T) {
+				do, recorder, lgr := setup(t)
+
+				resp := do(httptest.NewRequest(http.MethodGet, "/panic", nil))
+				assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+				spans := recorder.Ended()
+				require.Len(t, spans, 1)
+				assert.Equal(t, codes.Error, spans[0].Status().Code)
+				assert.Equal(t, int64(http.StatusInternalServerError), spanAttrs(spans[0])["http.status_code"].AsInt64())
+				assert.Equal(t, []string{"HTTP handler panic"}, lgr.messages("error"))
+			})
+
+			t.Run("capture", func(t *testing.T) {
+				config, recorder := newTestConfig(t)
+				config.RequestHeaders = []string{"X-Partner-ID", "Authorization-Missing"}
+				config.ResponseHeaders = []string{"X-Partner"}
+				config.BodyCapture = &BodyCaptureConfig{
+					Request:  true,
+					Response: true,
+					MaxSize:  32,
+					Redact:   RedactJSONFields("password"),
+				}
+				do := adapter.build(config)
+
+				payload := `{"user":"jane","password":"s3cret","note":"this payload is longer than 32 bytes"}`
+				req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(payload))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("X-Partner-ID", "42")
+				resp := do(req)
+				body, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+				assert.Equal(t, payload, string(body), "capture must not alter the response")
+
+				spans := recorder.Ended()
+				require.Len(t, spans, 1)
+				attrs := spanAttrs(spans[0])
+				assert.Equal(t, []string{"42"}, attrs["http.request.header.x-partner-id"].AsStringSlice())
+				assert.NotContains(t, attrs, "http.request.header.authorization-missing")
+				assert.Equal(t, []string{"acme"}, attrs["http.response.header.x-partner"].AsStringSlice())
+				assert.Equal(t, `{"user":"jane","password":"[Filtered]"`, attrs["http.request.body"].AsString())
+				assert.True(t, attrs["http.request.body.truncated"].AsBool())
+				assert.Equal(t, `{"user":"jane","password":"[Filtered]"`, attrs["http.response.body"].AsString())
+			})
+
+			t.Run("canonical log", func(t *testing.T) {
+				do, _, lgr := setup(t, func(c *Config) { c.CanonicalLog = true })
+
+				do(httptest.NewRequest(http.MethodGet, "/users/42", nil))
+				do(httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+				require.Len(t, lgr.entries, 2, "a single line per request")
+				success, failure := lgr.entries[0], lgr.entries[1]
+				assert.Equal(t, "info", success.level)
+				assert.Equal(t, "HTTP request", success.msg)
+				assert.Equal(t, []string{"bytes", "client_ip", "duration_ms", "method", "path", "remote_addr",
+					"request_id", "route", "span_id", "status", "trace_id", "user_agent", "user_id"}, logKeys(success))
+				assert.Equal(t, "42", success.keyvals[len(success.keyvals)-1])
+
+				assert.Equal(t, "error", failure.level)
+				assert.Contains(t, logKeys(failure), "error")
+			})
+
+			t.Run("access log", func(t *testing.T) {
+				var buf bytes.Buffer
+				do, _, _ := setup(t, func(c *Config) { c.AccessLog = &AccessLogConfig{Writer: &buf} })
+
+				req := httptest.NewRequest(http.MethodGet, "/users/42?q=1", nil)
+				req.Header.Set("Referer", "https://example.com/")
+				req.Header.Set("User-Agent", "conformance")
+				do(req)
+				do(httptest.NewRequest(http.MethodGet, "/health", nil))
+				do(httptest.NewRequest(http.MethodGet, "/quiet/ping", nil))
+
+				assert.Regexp(t, `^\S+ - - \[[^\]]+\] "GET /users/42\?q=1 HTTP/1\.1" 200 2 "https://example.com/" "conformance"\n$`,
+					buf.String(), "skipped requests are not logged")
+			})
+
+			t.Run("client ip", func(t *testing.T) {
+				var buf bytes.Buffer
+				do, recorder, lgr := setup(t, func(c *Config) {
+					// the test peers: httptest requests and fiber's app.Test
+					c.TrustedProxies, _ = ParseTrustedProxies("192.0.2.1", "0.0.0.0", "10.0.0.0/8")
+					c.AccessLog = &AccessLogConfig{Writer: &buf, Format: "%h"}
+				})
+
+				req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+				req.Header.Set("X-Forwarded-For", "198.51.100.9, 203.0.113.7, 10.1.2.3")
+				do(req)
+
+				spans := recorder.Ended()
+				require.Len(t, spans, 1)
+				assert.Equal(t, "203.0.113.7", spanAttrs(spans[0])["client.address"].AsString())
+				require.Len(t, lgr.entries, 2)
+				assert.Contains(t, lgr.entries[1].keyvals, "203.0.113.7")
+				assert.Equal(t, "203.0.113.7\n", buf.String())
+			})
+
+			t.Run("trace headers", func(t *testing.T) {
+				do, recorder, _ := setup(t, func(c *Config) {
+					c.TraceHeaders = &TraceHeadersConfig{DisableIDs: true, TraceResponse: true}
+					c.ServerTiming = true
+				})
+
+				resp := do(httptest.NewRequest(http.MethodGet, "/users/42", nil))
+				spans := recorder.Ended()
+				require.Len(t, spans, 1)
+				sc := spans[0].SpanContext()
+				assert.Equal(t, "00-"+sc.TraceID().String()+"-"+sc.SpanID().String()+"-01", resp.Header.Get(TraceResponseHeader))
+				assert.Empty(t, resp.Header.Get(TraceIDHeader), "disabled")
+				assert.Empty(t, resp.Header.Get(SpanIDHeader), "disabled")
+				assert.Regexp(t, `^total;dur=[0-9.]+, db;dur=5$`, resp.Header.Get(ServerTimingHeader))
+
+				// the framework error handler responds after the middleware returned
+				resp = do(httptest.NewRequest(http.MethodGet, "/fail", nil))
+				assert.Regexp(t, `^total;dur=[0-9.]+$`, resp.Header.Get(ServerTimingHeader))
+			})
+
+			t.Run("skip", func(t *testing.T) {
+				do, recorder, lgr := setup(t)
+
+				resp := do(httptest.NewRequest(http.MethodGet, "/health", nil))
+				assert.Empty(t, resp.Header.Get(RequestIDHeader))
+				assert.Empty(t, recorder.Ended())
+				assert.Empty(t, lgr.entries)
+			})
+
+			t.Run("skip logs only", func(t *testing.T) {
+				do, recorder, lgr := setup(t)
+
+				resp := do(httptest.NewRequest(http.MethodGet, "/quiet/ping", nil))
+				assert.NotEmpty(t, resp.Header.Get(RequestIDHeader))
+				assert.Len(t, recorder.Ended(), 1, "the request is still traced")
+				assert.Empty(t, lgr.entries)
+			})
+		})
+	}
+}
@@ -0,0 +1,429 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ubin/go-observability/middleware/httpobs"
+	obssentry "github.com/ubin/go-observability/telemetry/provider/sentry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// observer is the framework-agnostic request lifecycle shared by the middleware adapters.
+// Adapters describe the request with requestInfo, call start before the handler and
+// finish with the responseInfo it produced, so every framework gets the same request IDs,
+// trace headers, span attributes, metrics and logs.
+type observer struct {
+	config    *Config
+	metrics   *requestMetrics
+	accessLog *accessLog
+}
+
+// newObserver creates the observer of a middleware, config must not be nil
+func newObserver(config *Config) *observer {
+	return &observer{
+		config:    config,
+		metrics:   newServerMetrics(config),
+		accessLog: newAccessLog(config.AccessLog),
+	}
+}
+
+// requestInfo describes an incoming request independently of the framework
+type requestInfo struct {
+	method        string
+	path          string
+	proto         string
+	scheme        string
+	target        string
+	host          string
+	userAgent     string
+	remoteAddr    string
+	contentLength int64
+
+	// header reads the request headers, it is also used to extract the trace context
+	header propagation.TextMapCarrier
+	// headerValues returns all values of a request header
+	headerValues func(key string) []string
+	// setHeader sets a response header
+	setHeader func(key, value string)
+	// teeBody copies the request body, as read by the handler, to w
+	teeBody func(w io.Writer)
+	// teeResponse copies the response body written by the handler to w,
+	// nil for adapters passing the buffered body in responseInfo
+	teeResponse func(w io.Writer)
+	// beforeWrite registers a hook run once just before the response headers are written,
+	// nil for adapters buffering the response, whose headers can be set until finish
+	beforeWrite func(hook func())
+	// httpRequest returns the request for the Sentry scope, only called when Sentry is enabled
+	httpRequest func() *http.Request
+}
+
+// httpRequestInfo describes a net/http request, setHeader writes the response headers
+func httpRequestInfo(r *http.Request, setHeader func(key, value string)) requestInfo {
+	return requestInfo{
+		method:        r.Method,
+		path:          r.URL.Path,
+		proto:         r.Proto,
+		scheme:        requestScheme(r),
+		target:        r.URL.RequestURI(),
+		host:          r.Host,
+		userAgent:     r.UserAgent(),
+		remoteAddr:    r.RemoteAddr,
+		contentLength: r.ContentLength,
+		header:        propagation.HeaderCarrier(r.Header),
+		headerValues:  r.Header.Values,
+		setHeader:     setHeader,
+		teeBody: func(w io.Writer) {
+			if r.Body != nil && r.Body != http.NoBody {
+				r.Body = &teeReadCloser{ReadCloser: r.Body, w: w}
+			}
+		},
+		httpRequest: func() *http.Request { return r },
+	}
+}
+
+// teeReadCloser copies what is read from a request body to w
+type teeReadCloser struct {
+	io.ReadCloser
+	w io.Writer
+}
+
+// Read implements io.Reader
+func (t *teeReadCloser) Read(p []byte) (int, error) {
+	n, err := t.ReadCloser.Read(p)
+	if n > 0 {
+		t.w.Write(p[:n])
+	}
+	return n, err
+}
+
+// responseInfo is what the handler produced, read once it returned
+type responseInfo struct {
+	// route is the matched route template, NormalizePath(path) is used when empty
+	route string
+	// status is the response status, 0 when the framework error handler derives it from err
+	status int
+	bytes  int
+	err    error
+	// header returns all values of a response header
+	header func(key string) []string
+	// body is the buffered response body of adapters without teeResponse
+	body []byte
+}
+
+// inflight is a request being observed, created by start and completed by finish
+type inflight struct {
+	o           *observer
+	req         requestInfo
+	ctx         context.Context
+	startTime   time.Time
+	requestID   string
+	clientIP    string
+	traceID     string
+	spanID      string
+	span        trace.Span
+	hubScope    *sentryScope
+	activeAttrs metric.MeasurementOption
+	logging     bool
+	skipLogs    bool
+	annotations *httpobs.Annotations
+	panicked    bool
+	// timings are the phases reported in the Server-Timing header when ServerTiming is enabled
+	timings         *httpobs.Timings
+	serverTimingSet bool
+	// requestBody and responseBody capture the bodies when BodyCapture is enabled
+	requestBody  *capturedBody
+	responseBody *capturedBody
+	// err is the handler error reported with WriteError, for adapters without error returns
+	err error
+}
+
+type inflightKey struct{}
+
+// inflightFromContext returns the request observed by a middleware, or nil
+func inflightFromContext(ctx context.Context) *inflight {
+	f, _ := ctx.Value(inflightKey{}).(*inflight)
+	return f
+}
+
+// start assigns the request ID, binds the Sentry hub, starts the server span, sets the
+// response headers and logs the received request. The returned context must be used by the handler.
+// It returns a nil inflight when a skip rule excludes the request, adapters then call the
+// next handler untouched.
+func (o *observer) start(ctx context.Context, req requestInfo) (context.Context, *inflight) {
+	config := o.config
+	mode, skipped := config.matchSkip(req)
+	if skipped && mode == SkipAll {
+		return ctx, nil
+	}
+	f := &inflight{
+		o:         o,
+		req:       req,
+		startTime: time.Now(),
+		logging:   config.Logger != nil && !config.SkipLogging && !skipped,
+		skipLogs:  skipped,
+		clientIP:  config.clientIP(req),
+	}
+
+	// Generate request ID if enabled
+	if config.GenerateRequestID {
+		f.requestID = req.header.Get(RequestIDHeader)
+		if f.requestID == "" {
+			f.requestID = uuid.New().String()
+		}
+		req.setHeader(RequestIDHeader, f.requestID)
+	}
+
+	// Record request metrics whether or not tracing is enabled
+	f.activeAttrs = o.metrics.start(ctx, serverActiveAttrs(req.method, req.scheme)...)
+
+	// Bind a per-request Sentry hub carrying request, user and transaction data
+	if obssentry.Enabled() {
+		if r := req.httpRequest(); r != nil {
+			ctx, f.hubScope = startSentryScope(ctx, config, r.WithContext(ctx), fmt.Sprintf("%s %s", req.method, NormalizePath(req.path)))
+			if f.hubScope != nil && f.requestID != "" {
+				f.hubScope.hub.Scope().SetTag("request_id", f.requestID)
+			}
+		}
+	}
+
+	if tp := config.tracerProvider(); tp != nil {
+		// Extract trace context from incoming headers (for distributed tracing)
+		ctx = config.propagator().Extract(ctx, req.header)
+
+		// Name the span after the normalized path until the router reports the matched route
+		route := NormalizePath(req.path)
+		attrs := []attribute.KeyValue{
+			attribute.String("http.method", req.method),
+			attribute.String("http.path", req.path),
+			attribute.String("http.route", route),
+			attribute.String("http.scheme", req.scheme),
+			attribute.String("http.target", req.target),
+			attribute.String("http.host", req.host),
+			attribute.String("http.user_agent", req.userAgent),
+			attribute.String("http.remote_addr", req.remoteAddr),
+			attribute.String("client.address", f.clientIP),
+		}
+		if f.requestID != "" {
+			attrs = append(attrs, attribute.String("http.request_id", f.requestID))
+		}
+		ctx, f.span = tp.Tracer(config.ServiceName).Start(ctx,
+			fmt.Sprintf("%s %s", req.method, route),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attrs...),
+		)
+
+		if f.span.IsRecording() {
+			f.span.SetAttributes(headerAttributes("http.request.header.", config.RequestHeaders, req.headerValues)...)
+			f.startBodyCapture()
+		}
+
+		// Add trace context to response headers
+		spanContext := f.span.SpanContext()
+		f.traceID = spanContext.TraceID().String()
+		f.spanID = spanContext.SpanID().String()
+		f.setTraceHeaders(spanContext)
+	}
+	if config.ServerTiming {
+		// handlers record phases with httpobs.RecordTiming
+		ctx, f.timings = httpobs.NewTimingContext(ctx)
+		if req.beforeWrite != nil {
+			req.beforeWrite(f.setServerTiming)
+		}
+	}
+	ctx = context.WithValue(ctx, inflightKey{}, f)
+	f.ctx = ctx
+
+	if f.logging {
+		// handlers add fields to the completed log line with httpobs.Annotate
+		ctx, f.annotations = httpobs.NewContext(ctx)
+		f.ctx = ctx
+		if !config.CanonicalLog {
+			config.Logger.InfoContext(ctx, "HTTP request received", f.logAttrs()...)
+		}
+	}
+	return ctx, f
+}
+
+// startBodyCapture tees the request and response bodies enabled by config.BodyCapture
+func (f *inflight) startBodyCapture() {
+	capture := f.o.config.BodyCapture
+	if capture == nil {
+		return
+	}
+	if capture.Request && capture.captures(f.req.header.Get("Content-Type")) {
+		f.requestBody = newCapturedBody(capture.maxSize())
+		f.req.teeBody(f.requestBody)
+	}
+	if capture.Response {
+		// the content type is only known once the handler ran, it is checked in finish
+		f.responseBody = newCapturedBody(capture.maxSize())
+		if f.req.teeResponse != nil {
+			f.req.teeResponse(f.responseBody)
+		}
+	}
+}
+
+// recordCapture records the allow-listed response headers and the captured bodies on the span
+func (f *inflight) recordCapture(resp responseInfo) {
+	config := f.o.config
+	if resp.header != nil {
+		f.span.SetAttributes(headerAttributes("http.response.header.", config.ResponseHeaders, resp.header)...)
+	}
+	if f.requestBody != nil {
+		f.span.SetAttributes(config.BodyCapture.attributes("http.request.body", f.requestBody)...)
+	}
+	if f.responseBody != nil && resp.header != nil {
+		if f.req.teeResponse == nil {
+			f.responseBody.Write(resp.body)
+		}
+		if contentType := resp.header("Content-Type"); len(contentType) > 0 && config.BodyCapture.captures(contentType[0]) {
+			f.span.SetAttributes(config.BodyCapture.attributes("http.response.body", f.responseBody)...)
+		}
+	}
+}
+
+// panic records a recovered panic, finish then reports the request as a 500
+func (f *inflight) panic(recovered any) {
+	f.panicked = true
+	handlePanic(f.ctx, f.o.config, f.hubScope, recovered, f.req.method, f.req.path)
+}
+
+// finish records the response on the span, the Sentry scope and the metrics, ends the span
+// and logs the completed request
+func (f *inflight) finish(resp responseInfo) {
+	duration := time.Since(f.startTime)
+	// the headers of empty and buffered responses are written once the middleware returned
+	f.setServerTiming()
+
+	route := resp.route
+	if route == "" {
+		route = NormalizePath(f.req.path)
+	}
+	// handler errors decide the status when the framework error handler did not respond yet
+	if resp.err == nil {
+		resp.err = f.err
+	}
+	aborted := !f.panicked && clientAborted(f.ctx, resp.err)
+	status := resp.status
+	switch {
+	case f.panicked:
+		status = http.StatusInternalServerError
+	case aborted && (status == 0 || errors.Is(resp.err, context.Canceled)):
+		// nobody received the status the handler or the framework responded with
+		status = StatusClientClosedRequest
+	case status == 0 && resp.err != nil:
+		status = errorStatus(resp.err)
+	}
+	outcome := RequestStatus{
+		Method:        f.req.method,
+		Route:         route,
+		Status:        status,
+		Err:           resp.err,
+		ClientAborted: aborted,
+	}
+	failed := f.o.config.statusClassifier()(outcome)
+
+	// the normalized path of unmatched requests is client controlled, keep it out of the metrics
+	f.o.metrics.end(f.ctx, f.activeAttrs, serverAttrs(f.req.method, resp.route), status,
+		duration, f.req.contentLength, int64(resp.bytes))
+	f.hubScope.setTransaction(fmt.Sprintf("%s %s", f.req.method, route))
+
+	if f.span != nil {
+		f.span.SetName(fmt.Sprintf("%s %s", f.req.method, route))
+		f.span.SetAttributes(
+			attribute.String("http.route", route),
+			attribute.Int("http.status_code", status),
+			attribute.Int("http.response_size", resp.bytes),
+		)
+		if aborted {
+			f.span.SetAttributes(attribute.Bool("http.client_aborted", true))
+		}
+		if f.span.IsRecording() {
+			f.recordCapture(resp)
+		}
+		// Only classified failures set the span status, other handler errors, e.g. a user
+		// error answered with a 4xx, are recorded as events of a successful span
+		if failed {
+			f.span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", status))
+			f.span.SetAttributes(attribute.Bool("error", true))
+		}
+		if resp.err != nil && (failed || !aborted) {
+			recordError(f.span, resp.err)
+		}
+		f.span.End()
+	}
+
+	if f.logging {
+		f.logCompleted(outcome, failed, duration, resp)
+	}
+	if !f.skipLogs {
+		f.o.accessLog.write(&accessLogEntry{
+			req:       &f.req,
+			start:     f.startTime,
+			duration:  duration,
+			status:    status,
+			bytes:     resp.bytes,
+			header:    resp.header,
+			clientIP:  f.clientIP,
+			requestID: f.requestID,
+			traceID:   f.traceID,
+		})
+	}
+}
+
+// logCompleted logs the completed request with the handler annotations. The canonical log line
+// also carries the user agent and the handler error, and is logged as an error for failed requests.
+func (f *inflight) logCompleted(outcome RequestStatus, failed bool, duration time.Duration, resp responseInfo) {
+	config := f.o.config
+	attrs := append(f.logAttrs(),
+		"route", outcome.Route,
+		"status", outcome.Status,
+		"duration_ms", duration.Milliseconds(),
+		"bytes", resp.bytes)
+	if outcome.ClientAborted {
+		attrs = append(attrs, "client_aborted", true)
+	}
+	if !config.CanonicalLog {
+		attrs = append(attrs, f.annotations.Fields()...)
+		config.Logger.InfoContext(f.ctx, "HTTP request completed", attrs...)
+		return
+	}
+
+	attrs = append(attrs, "user_agent", f.req.userAgent)
+	if resp.err != nil {
+		attrs = append(attrs, "error", resp.err)
+	}
+	attrs = append(attrs, f.annotations.Fields()...)
+	if failed {
+		config.Logger.ErrorContext(f.ctx, "HTTP request", attrs...)
+	} else {
+		config.Logger.InfoContext(f.ctx, "HTTP request", attrs...)
+	}
+}
+
+// logAttrs returns the request fields shared by the received and completed logs
+func (f *inflight) logAttrs() []any {
+	attrs := []any{
+		"method", f.req.method,
+		"path", f.req.path,
+		"remote_addr", f.req.remoteAddr,
+		"client_ip", f.clientIP,
+	}
+	if f.requestID != "" {
+		attrs = append(attrs, "request_id", f.requestID)
+	}
+	if f.traceID != "" {
+		attrs = append(attrs, "trace_id", f.traceID, "span_id", f.spanID)
+	}
+	return attrs
+}
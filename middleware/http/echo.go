@@ -0,0 +1,75 @@
+package http
+
+import (
+	"io"
+
+	"github.com/labstack/echo/v4"
+	obserrors "github.com/ubin/go-observability/errors"
+)
+
+// EchoMiddleware returns an Echo middleware that adds OpenTelemetry tracing.
+// Spans are named after the matched route (c.Path) and returned errors are recorded on the span.
+func EchoMiddleware(config *Config) echo.MiddlewareFunc {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	o := newObserver(config)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			info := httpRequestInfo(c.Request(), c.Response().Header().Set)
+			info.teeResponse = func(w io.Writer) {
+				rw := newResponseWriter(c.Response().Writer)
+				rw.teeBody(w)
+				c.Response().Writer = rw
+			}
+			info.beforeWrite = c.Response().Before
+			ctx, f := o.start(c.Request().Context(), info)
+			// Skip if a skip rule matched
+			if f == nil {
+				return next(c)
+			}
+			c.SetRequest(c.Request().WithContext(ctx))
+			if f.requestID != "" {
+				c.Set("request_id", f.requestID)
+			}
+
+			// the handler error is recorded as returned, before its conversion below
+			var handlerErr error
+			defer func() {
+				recovered := recover()
+				if recovered != nil {
+					f.panic(recovered)
+					err = echo.ErrInternalServerError
+				}
+
+				// Echo's error handler responds after the middleware returns
+				status := c.Response().Status
+				if handlerErr != nil && !c.Response().Committed {
+					status = 0
+				}
+				f.finish(responseInfo{
+					route:  c.Path(),
+					status: status,
+					bytes:  int(c.Response().Size),
+					err:    handlerErr,
+					header: c.Response().Header().Values,
+				})
+
+				if recovered != nil && !config.RecoverPanics {
+					panic(recovered)
+				}
+			}()
+
+			handlerErr = next(c)
+			err = handlerErr
+
+			// Structured errors are converted so Echo's error handler responds with their
+			// status without leaking internal messages
+			if _, ok := obserrors.As(err); ok {
+				err = echo.NewHTTPError(obserrors.HTTPStatus(err), obserrors.PublicMessage(err))
+			}
+			return err
+		}
+	}
+}
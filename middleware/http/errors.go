@@ -0,0 +1,62 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/labstack/echo/v4"
+	obserrors "github.com/ubin/go-observability/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WriteError reports err to the middleware, which records it like the errors returned by
+// Fiber and Echo handlers, and writes the HTTP status mapped from it. Only user errors expose
+// their message to the client, other kinds get the generic status text.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	status := obserrors.HTTPStatus(err)
+	if f := inflightFromContext(r.Context()); f != nil {
+		f.err = err
+	} else {
+		recordError(trace.SpanFromContext(r.Context()), err)
+	}
+	http.Error(w, obserrors.PublicMessage(err), status)
+}
+
+// errorStatus returns the HTTP status a handler error should produce
+func errorStatus(err error) int {
+	var fe *fiber.Error
+	if errors.As(err, &fe) {
+		return fe.Code
+	}
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		return he.Code
+	}
+	return obserrors.HTTPStatus(err)
+}
+
+// recordError records err on the span with its classification, attributes and stack trace
+func recordError(span trace.Span, err error) {
+	if !span.IsRecording() {
+		return
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("error.kind", obserrors.KindOf(err).String()),
+	}
+	keyvals := obserrors.Attrs(err)
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		key, ok := keyvals[i].(string)
+		if !ok {
+			continue
+		}
+		attrs = append(attrs, attribute.String(key, fmt.Sprintf("%v", keyvals[i+1])))
+	}
+	if stack := obserrors.Stack(err); stack != "" {
+		attrs = append(attrs, attribute.String("exception.stacktrace", stack))
+	}
+	span.RecordError(err, trace.WithAttributes(attrs...))
+}
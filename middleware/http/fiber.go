@@ -1,16 +1,12 @@
 package http
 
 import (
-	"fmt"
-	"time"
+	"io"
+	"net/http"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/google/uuid"
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/codes"
-	"go.opentelemetry.io/otel/propagation"
-	"go.opentelemetry.io/otel/trace"
+	obserrors "github.com/ubin/go-observability/errors"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
 )
 
 // FiberMiddleware returns a Fiber middleware that adds OpenTelemetry tracing
@@ -18,136 +14,142 @@ func FiberMiddleware(config *Config) fiber.Handler {
 	if config == nil {
 		config = DefaultConfig()
 	}
+	o := newObserver(config)
 
-	return func(c *fiber.Ctx) error {
-		// Skip if path is in skip list
-		if config.shouldSkipPath(c.Path()) {
+	return func(c *fiber.Ctx) (err error) {
+		ctx, f := o.start(c.UserContext(), fiberRequestInfo(c))
+		// Skip if a skip rule matched
+		if f == nil {
 			return c.Next()
 		}
 
-		startTime := time.Now()
-
-		// Generate request ID if enabled
-		var requestID string
-		if config.GenerateRequestID {
-			requestID = c.Get(RequestIDHeader)
-			if requestID == "" {
-				requestID = uuid.New().String()
-			}
-			c.Set(RequestIDHeader, requestID)
-			c.Locals("request_id", requestID)
-		}
-
-		// Skip tracing if no tracer provider
-		if config.TracerProvider == nil {
-			// Still log the request if logger is configured
-			if config.Logger != nil && !config.SkipLogging {
-				defer func() {
-					config.Logger.InfoContext(c.UserContext(), "HTTP request completed",
-						"method", c.Method(),
-						"path", c.Path(),
-						"status", c.Response().StatusCode(),
-						"duration_ms", time.Since(startTime).Milliseconds(),
-						"request_id", requestID)
-				}()
-			}
-			return c.Next()
-		}
-
-		// Extract trace context from incoming headers (for distributed tracing)
-		ctx := c.UserContext()
-		carrier := make(propagation.MapCarrier)
-		c.Request().Header.VisitAll(func(key, value []byte) {
-			carrier[string(key)] = string(value)
-		})
-		ctx = otel.GetTextMapPropagator().Extract(ctx, carrier)
-
-		// Get route path safely (c.Route() can be nil if route not matched)
-		routePath := c.Path()
-		if route := c.Route(); route != nil {
-			routePath = route.Path
+		// Store request and trace info in Fiber locals, the traced context in the user context
+		if f.requestID != "" {
+			c.Locals("request_id", f.requestID)
 		}
-		// Create a span for this HTTP request
-		tracer := otel.Tracer(config.ServiceName)
-		ctx, span := tracer.Start(ctx, fmt.Sprintf("%s %s", c.Method(), routePath),
-			trace.WithSpanKind(trace.SpanKindServer),
-			trace.WithAttributes(
-				attribute.String("http.method", c.Method()),
-				attribute.String("http.path", c.Path()),
-				attribute.String("http.route", routePath),
-				attribute.String("http.scheme", c.Protocol()),
-				attribute.String("http.target", string(c.Request().RequestURI())),
-				attribute.String("http.host", c.Hostname()),
-				attribute.String("http.user_agent", c.Get("User-Agent")),
-				attribute.String("http.remote_addr", c.IP()),
-			),
-		)
-		defer span.End()
-
-		// Add request ID to span
-		if requestID != "" {
-			span.SetAttributes(attribute.String("http.request_id", requestID))
+		c.Locals("client_ip", f.clientIP)
+		if f.traceID != "" {
+			c.Locals("trace_id", f.traceID)
+			c.Locals("span_id", f.spanID)
 		}
+		c.SetUserContext(ctx)
 
-		// Extract trace and span IDs
-		spanContext := span.SpanContext()
-		traceID := spanContext.TraceID().String()
-		spanID := spanContext.SpanID().String()
+		// the route matched so far is the one this middleware was registered with
+		useRoute := c.Route()
 
-		// Add trace context to response headers
-		c.Set(TraceIDHeader, traceID)
-		c.Set(SpanIDHeader, spanID)
+		// the handler error is recorded as returned, before its conversion below
+		var handlerErr error
+		defer func() {
+			recovered := recover()
+			if recovered != nil {
+				f.panic(recovered)
+				err = fiber.ErrInternalServerError
+			}
 
-		// Store trace info in Fiber locals
-		c.Locals("trace_id", traceID)
-		c.Locals("span_id", spanID)
+			// Fiber's error handler responds after the middleware returns
+			status := c.Response().StatusCode()
+			if handlerErr != nil {
+				status = 0
+			}
+			// Unmatched requests are left on this middleware's Use route, or on the handler-less
+			// fallback route of Fiber's error handler: report them without a route as the other
+			// adapters do
+			var routePath string
+			if route := c.Route(); route != nil && route != useRoute && len(route.Handlers) > 0 {
+				routePath = route.Path
+			}
+			f.finish(responseInfo{
+				route:  routePath,
+				status: status,
+				bytes:  len(c.Response().Body()),
+				err:    handlerErr,
+				header: func(key string) []string { return byteValues(c.Response().Header.PeekAll(key)) },
+				body:   c.Response().Body(),
+			})
+
+			if recovered != nil && !config.RecoverPanics {
+				panic(recovered)
+			}
+		}()
 
-		// Store context in Fiber context
-		c.SetUserContext(ctx)
+		handlerErr = c.Next()
+		err = handlerErr
 
-		// Log the incoming request
-		if config.Logger != nil && !config.SkipLogging {
-			config.Logger.InfoContext(ctx, "HTTP request received",
-				"method", c.Method(),
-				"path", c.Path(),
-				"remote_addr", c.IP(),
-				"request_id", requestID)
+		// Structured errors are converted so Fiber's error handler responds with their
+		// status without leaking internal messages
+		if _, ok := obserrors.As(err); ok {
+			err = fiber.NewError(obserrors.HTTPStatus(err), obserrors.PublicMessage(err))
 		}
+		return err
+	}
+}
 
-		// Handle the request
-		err := c.Next()
-
-		// Record response details in span
-		statusCode := c.Response().StatusCode()
-		span.SetAttributes(
-			attribute.Int("http.status_code", statusCode),
-			attribute.Int("http.response_size", len(c.Response().Body())),
-		)
+// fiberRequestInfo describes a Fiber request for the shared request lifecycle
+func fiberRequestInfo(c *fiber.Ctx) requestInfo {
+	// skip predicates and the Sentry scope need a net/http request, it is converted once
+	var converted *http.Request
+	var convertErr error
+	return requestInfo{
+		method:        c.Method(),
+		path:          c.Path(),
+		proto:         string(c.Request().Header.Protocol()),
+		scheme:        c.Protocol(),
+		target:        string(c.Request().RequestURI()),
+		host:          c.Hostname(),
+		userAgent:     c.Get(fiber.HeaderUserAgent),
+		remoteAddr:    c.Context().RemoteAddr().String(),
+		contentLength: int64(len(c.Request().Body())),
+		header:        fiberHeaderCarrier{c: c},
+		headerValues:  func(key string) []string { return byteValues(c.Request().Header.PeekAll(key)) },
+		setHeader:     c.Set,
+		teeBody: func(w io.Writer) {
+			// Fiber buffers the request body
+			w.Write(c.Request().Body())
+		},
+		httpRequest: func() *http.Request {
+			if converted == nil && convertErr == nil {
+				converted = new(http.Request)
+				if convertErr = fasthttpadaptor.ConvertRequest(c.Context(), converted, true); convertErr != nil {
+					converted = nil
+				}
+			}
+			return converted
+		},
+	}
+}
 
-		// Set span status based on HTTP status code
-		if statusCode >= 500 {
-			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", statusCode))
-			span.SetAttributes(attribute.Bool("error", true))
+// fiberHeaderCarrier adapts the Fiber request headers to propagation.TextMapCarrier
+type fiberHeaderCarrier struct {
+	c *fiber.Ctx
+}
 
-			// Record error if returned
-			if err != nil {
-				span.RecordError(err)
-			}
+// Get implements propagation.TextMapCarrier
+func (h fiberHeaderCarrier) Get(key string) string {
+	return h.c.Get(key)
+}
 
-		}
+// Set implements propagation.TextMapCarrier
+func (h fiberHeaderCarrier) Set(key, value string) {
+	h.c.Request().Header.Set(key, value)
+}
 
-		// Log the completed request
-		if config.Logger != nil && !config.SkipLogging {
-			duration := time.Since(startTime)
-			config.Logger.InfoContext(ctx, "HTTP request completed",
-				"method", c.Method(),
-				"path", c.Path(),
-				"status", statusCode,
-				"duration_ms", duration.Milliseconds(),
-				"bytes", len(c.Response().Body()),
-				"request_id", requestID)
-		}
+// Keys implements propagation.TextMapCarrier
+func (h fiberHeaderCarrier) Keys() []string {
+	var keys []string
+	h.c.Request().Header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
 
-		return err
+// byteValues converts fasthttp header values to strings
+func byteValues(values [][]byte) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = string(v)
 	}
+	return s
 }
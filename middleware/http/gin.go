@@ -0,0 +1,124 @@
+package http
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GinMiddleware returns a Gin middleware that adds OpenTelemetry tracing.
+// Spans are named after the matched route (c.FullPath) and errors added with c.Error are
+// recorded on the span.
+func GinMiddleware(config *Config) gin.HandlerFunc {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	o := newObserver(config)
+
+	return func(c *gin.Context) {
+		info := httpRequestInfo(c.Request, c.Header)
+		info.teeResponse = func(w io.Writer) {
+			c.Writer = &ginBodyWriter{ResponseWriter: c.Writer, w: w}
+		}
+		info.beforeWrite = func(hook func()) {
+			c.Writer = &ginHeaderWriter{ResponseWriter: c.Writer, hook: hook}
+		}
+		ctx, f := o.start(c.Request.Context(), info)
+		// Skip if a skip rule matched
+		if f == nil {
+			c.Next()
+			return
+		}
+		c.Request = c.Request.WithContext(ctx)
+		if f.requestID != "" {
+			c.Set("request_id", f.requestID)
+		}
+
+		defer func() {
+			recovered := recover()
+			if recovered != nil {
+				f.panic(recovered)
+				if config.RecoverPanics {
+					c.AbortWithStatus(http.StatusInternalServerError)
+				}
+			}
+
+			var err error
+			if last := c.Errors.Last(); last != nil {
+				err = last.Err
+			}
+			f.finish(responseInfo{
+				route:  c.FullPath(),
+				status: c.Writer.Status(),
+				bytes:  max(c.Writer.Size(), 0),
+				err:    err,
+				header: c.Writer.Header().Values,
+			})
+
+			if recovered != nil && !config.RecoverPanics {
+				panic(recovered)
+			}
+		}()
+
+		c.Next()
+	}
+}
+
+// ginBodyWriter copies the response body written through Gin to w
+type ginBodyWriter struct {
+	gin.ResponseWriter
+	w io.Writer
+}
+
+// Write implements io.Writer
+func (b *ginBodyWriter) Write(p []byte) (int, error) {
+	n, err := b.ResponseWriter.Write(p)
+	b.w.Write(p[:n])
+	return n, err
+}
+
+// WriteString implements io.StringWriter
+func (b *ginBodyWriter) WriteString(s string) (int, error) {
+	n, err := b.ResponseWriter.WriteString(s)
+	b.w.Write([]byte(s[:n]))
+	return n, err
+}
+
+// ginHeaderWriter runs hook once before Gin writes the response headers
+type ginHeaderWriter struct {
+	gin.ResponseWriter
+	hook func()
+}
+
+func (h *ginHeaderWriter) beforeWrite() {
+	if h.hook != nil && !h.Written() {
+		hook := h.hook
+		h.hook = nil
+		hook()
+	}
+}
+
+// WriteHeaderNow implements gin.ResponseWriter
+func (h *ginHeaderWriter) WriteHeaderNow() {
+	h.beforeWrite()
+	h.ResponseWriter.WriteHeaderNow()
+}
+
+// Write implements io.Writer
+func (h *ginHeaderWriter) Write(p []byte) (int, error) {
+	h.beforeWrite()
+	return h.ResponseWriter.Write(p)
+}
+
+// WriteString implements io.StringWriter
+func (h *ginHeaderWriter) WriteString(s string) (int, error) {
+	h.beforeWrite()
+	return h.ResponseWriter.WriteString(s)
+}
+
+// Flush implements http.Flusher
+func (h *ginHeaderWriter) Flush() {
+	h.beforeWrite()
+	h.ResponseWriter.Flush()
+}
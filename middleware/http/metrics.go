@@ -0,0 +1,142 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// meterName is the instrumentation scope of the middleware metrics
+const meterName = "github.com/ubin/go-observability/middleware/http"
+
+// durationBuckets are the semantic convention boundaries for http.*.request.duration (seconds)
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.25, 0.5, 0.75, 1, 2.5, 5, 7.5, 10}
+
+// requestMetrics records the OpenTelemetry HTTP metrics (requests, errors, duration)
+// of either the server or the client side
+type requestMetrics struct {
+	duration     metric.Float64Histogram
+	active       metric.Int64UpDownCounter
+	requestSize  metric.Int64Histogram
+	responseSize metric.Int64Histogram
+}
+
+// newServerMetrics creates the http.server.* instruments, it returns nil when no MeterProvider is configured
+func newServerMetrics(config *Config) *requestMetrics {
+	return newRequestMetrics(config, "http.server", "server")
+}
+
+// newClientMetrics creates the http.client.* instruments, it returns nil when no MeterProvider is configured
+func newClientMetrics(config *Config) *requestMetrics {
+	return newRequestMetrics(config, "http.client", "client")
+}
+
+func newRequestMetrics(config *Config, prefix, side string) *requestMetrics {
+	if config.MeterProvider == nil {
+		return nil
+	}
+	meter := config.MeterProvider.Meter(meterName)
+
+	// instrument creation only fails on invalid names, fall back to the no-op instruments returned alongside
+	duration, _ := meter.Float64Histogram(prefix+".request.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of HTTP "+side+" requests."),
+		metric.WithExplicitBucketBoundaries(durationBuckets...))
+	active, _ := meter.Int64UpDownCounter(prefix+".active_requests",
+		metric.WithUnit("{request}"),
+		metric.WithDescription("Number of active HTTP "+side+" requests."))
+	requestSize, _ := meter.Int64Histogram(prefix+".request.body.size",
+		metric.WithUnit("By"),
+		metric.WithDescription("Size of HTTP "+side+" request bodies."))
+	responseSize, _ := meter.Int64Histogram(prefix+".response.body.size",
+		metric.WithUnit("By"),
+		metric.WithDescription("Size of HTTP "+side+" response bodies."))
+
+	return &requestMetrics{
+		duration:     duration,
+		active:       active,
+		requestSize:  requestSize,
+		responseSize: responseSize,
+	}
+}
+
+// start counts an active request and returns the attributes to pass to end
+func (m *requestMetrics) start(ctx context.Context, attrs ...attribute.KeyValue) metric.MeasurementOption {
+	if m == nil {
+		return nil
+	}
+	activeAttrs := metric.WithAttributes(attrs...)
+	m.active.Add(ctx, 1, activeAttrs)
+	return activeAttrs
+}
+
+// end records a completed request, labeled by attrs and the status class
+func (m *requestMetrics) end(ctx context.Context, activeAttrs metric.MeasurementOption, attrs []attribute.KeyValue, status int, duration time.Duration, requestSize, responseSize int64) {
+	if m == nil {
+		return
+	}
+	m.active.Add(ctx, -1, activeAttrs)
+
+	recordAttrs := metric.WithAttributes(append(attrs, attribute.String("http.response.status_class", statusClass(status)))...)
+	m.duration.Record(ctx, duration.Seconds(), recordAttrs)
+	if requestSize >= 0 {
+		m.requestSize.Record(ctx, requestSize, recordAttrs)
+	}
+	m.responseSize.Record(ctx, responseSize, recordAttrs)
+}
+
+// statusClass groups status codes by their first digit, e.g. 404 becomes "4xx".
+// Requests that failed without a response (status 0) are reported as "error".
+func statusClass(status int) string {
+	if status == 0 {
+		return "error"
+	}
+	if status < 100 || status > 599 {
+		return "unknown"
+	}
+	return strconv.Itoa(status/100) + "xx"
+}
+
+// knownMethods are the methods recorded as is in http.request.method, see metricMethod
+var knownMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodConnect: true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+}
+
+// metricMethod returns the method to label metrics with. Methods are client controlled, as in
+// the semantic conventions the ones outside the standard set are recorded as "_OTHER".
+func metricMethod(method string) string {
+	if knownMethods[method] {
+		return method
+	}
+	return "_OTHER"
+}
+
+// serverActiveAttrs labels http.server.active_requests
+func serverActiveAttrs(method, scheme string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("http.request.method", metricMethod(method)),
+		attribute.String("url.scheme", scheme),
+	}
+}
+
+// serverAttrs labels the completed server request metrics, http.route is left out when no
+// route matched
+func serverAttrs(method, route string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{attribute.String("http.request.method", metricMethod(method))}
+	if route != "" {
+		attrs = append(attrs, attribute.String("http.route", route))
+	}
+	return attrs
+}
@@ -0,0 +1,166 @@
+package http
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func collect(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Aggregation {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(t.Context(), &rm))
+	metrics := make(map[string]metricdata.Aggregation)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+	return metrics
+}
+
+func assertREDMetrics(t *testing.T, reader *sdkmetric.ManualReader, route, statusClass string) {
+	t.Helper()
+	metrics := collect(t, reader)
+
+	duration, ok := metrics["http.server.request.duration"].(metricdata.Histogram[float64])
+	require.True(t, ok, "duration histogram should be recorded")
+	require.Len(t, duration.DataPoints, 1)
+	point := duration.DataPoints[0]
+	assert.Equal(t, uint64(1), point.Count)
+	assert.Equal(t, attribute.NewSet(
+		attribute.String("http.request.method", http.MethodGet),
+		attribute.String("http.route", route),
+		attribute.String("http.response.status_class", statusClass),
+	), point.Attributes)
+
+	active, ok := metrics["http.server.active_requests"].(metricdata.Sum[int64])
+	require.True(t, ok, "active requests counter should be recorded")
+	require.Len(t, active.DataPoints, 1)
+	assert.Equal(t, int64(0), active.DataPoints[0].Value, "request should no longer be active")
+
+	responseSize, ok := metrics["http.server.response.body.size"].(metricdata.Histogram[int64])
+	require.True(t, ok, "response size histogram should be recorded")
+	assert.Equal(t, int64(len("created")), responseSize.DataPoints[0].Sum)
+}
+
+func TestMiddleware_RecordsMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	config := DefaultConfig()
+	config.MeterProvider = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	m := http.NewServeMux()
+	m.HandleFunc("GET /carts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("created"))
+	})
+	serve(t, Middleware(config)(m), "/carts/7")
+
+	assertREDMetrics(t, reader, "/carts/{id}", "2xx")
+}
+
+func TestFiberMiddleware_RecordsMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	config := DefaultConfig()
+	config.MeterProvider = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	app := fiber.New()
+	app.Use(FiberMiddleware(config))
+	app.Get("/carts/:id", func(c *fiber.Ctx) error {
+		return c.Status(http.StatusNotFound).SendString("created")
+	})
+	resp, err := app.Test(httptestRequest(http.MethodGet, "/carts/7"))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	assertREDMetrics(t, reader, "/carts/:id", "4xx")
+}
+
+func TestFiberMiddleware_UnmatchedRouteMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	config := DefaultConfig()
+	config.MeterProvider = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	app := fiber.New()
+	app.Use(FiberMiddleware(config))
+	app.Get("/carts/:id", func(c *fiber.Ctx) error { return nil })
+	resp, err := app.Test(httptestRequest(http.MethodGet, "/nope/123"))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	duration := collect(t, reader)["http.server.request.duration"].(metricdata.Histogram[float64])
+	require.Len(t, duration.DataPoints, 1)
+	assert.Equal(t, attribute.NewSet(
+		attribute.String("http.request.method", http.MethodGet),
+		attribute.String("http.response.status_class", "4xx"),
+	), duration.DataPoints[0].Attributes, "the middleware's own route is not a match")
+}
+
+func TestMiddleware_MetricsCardinalityIsBounded(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/carts/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		name string
+		use  func(config *Config) func(method, target string)
+	}{
+		{
+			name: "net/http",
+			use: func(config *Config) func(method, target string) {
+				handler := Middleware(config)(mux)
+				return func(method, target string) {
+					handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, target, nil))
+				}
+			},
+		},
+		{
+			name: "fiber",
+			use: func(config *Config) func(method, target string) {
+				app := fiber.New()
+				app.Use(FiberMiddleware(config))
+				app.Get("/carts/:id", func(c *fiber.Ctx) error { return nil })
+				return func(method, target string) {
+					_, err := app.Test(httptestRequest(method, target))
+					require.NoError(t, err)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := sdkmetric.NewManualReader()
+			config := DefaultConfig()
+			config.MeterProvider = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+			do := tt.use(config)
+
+			for i := 0; i < 50; i++ {
+				do(http.MethodGet, fmt.Sprintf("/carts/%d", i))
+				do(http.MethodGet, fmt.Sprintf("/probe/%x/admin.php", rand.Int63()))
+				do(fmt.Sprintf("M%d", rand.Int63()), "/carts/7")
+			}
+
+			metrics := collect(t, reader)
+			duration := metrics["http.server.request.duration"].(metricdata.Histogram[float64])
+			active := metrics["http.server.active_requests"].(metricdata.Sum[int64])
+			// matched and unmatched routes by GET and _OTHER, times the status classes
+			assert.LessOrEqual(t, len(duration.DataPoints), 6)
+			assert.LessOrEqual(t, len(active.DataPoints), 2)
+			for _, point := range duration.DataPoints {
+				method, _ := point.Attributes.Value("http.request.method")
+				assert.Contains(t, []string{http.MethodGet, "_OTHER"}, method.AsString())
+				if route, ok := point.Attributes.Value("http.route"); ok {
+					assert.NotContains(t, route.AsString(), "/probe/")
+				}
+			}
+		})
+	}
+}
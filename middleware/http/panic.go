@@ -0,0 +1,42 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	obssentry "github.com/ubin/go-observability/telemetry/provider/sentry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// handlePanic records a recovered panic on the request span with its stack trace, reports it
+// to Sentry when enabled and logs it. Callers decide whether to re-panic or respond 500.
+func handlePanic(ctx context.Context, config *Config, hubScope *sentryScope, recovered any, method, path string) {
+	stack := string(debug.Stack())
+
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(fmt.Errorf("panic: %v", recovered), trace.WithAttributes(
+		attribute.String("exception.stacktrace", stack),
+	))
+	span.SetStatus(codes.Error, "panic recovered")
+	span.SetAttributes(
+		attribute.Bool("error", true),
+		attribute.Int("http.status_code", 500),
+	)
+
+	if hubScope != nil {
+		hubScope.hub.RecoverWithContext(ctx, recovered)
+		// the slog backend reports error logs to Sentry, keep it from sending the panic twice
+		ctx = obssentry.WithReported(ctx)
+	}
+
+	if config.Logger != nil {
+		config.Logger.ErrorContext(ctx, "HTTP handler panic",
+			"error", recovered,
+			"method", method,
+			"path", path,
+			"stack", stack)
+	}
+}
@@ -0,0 +1,120 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	logconfig "github.com/ubin/go-observability/logger/loggerfactory/config"
+	"github.com/ubin/go-observability/logger/loggerfactory/defaultlogger"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func panicking(w http.ResponseWriter, r *http.Request) {
+	panic("boom")
+}
+
+func assertPanicSpan(t *testing.T, recorder *tracetest.SpanRecorder) {
+	t.Helper()
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	// the SDK adds its own exception event when the span ends while re-panicking
+	require.NotEmpty(t, spans[0].Events())
+	var stack string
+	for _, attr := range spans[0].Events()[0].Attributes {
+		if attr.Key == "exception.stacktrace" {
+			stack = attr.Value.AsString()
+		}
+	}
+	assert.Contains(t, stack, "panicking", "the stack trace should point at the handler")
+}
+
+func TestMiddleware_Repanics(t *testing.T) {
+	config, recorder := newTestConfig(t)
+	lgr := &recordingLogger{}
+	config.Logger = lgr
+
+	assert.PanicsWithValue(t, "boom", func() {
+		serve(t, Middleware(config)(http.HandlerFunc(panicking)), "/")
+	})
+	assertPanicSpan(t, recorder)
+	assert.Equal(t, []string{"HTTP handler panic"}, lgr.messages("error"))
+}
+
+func TestMiddleware_RecoverPanics(t *testing.T) {
+	config, recorder := newTestConfig(t)
+	config.RecoverPanics = true
+
+	w := httptest.NewRecorder()
+	assert.NotPanics(t, func() {
+		Middleware(config)(http.HandlerFunc(panicking)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assertPanicSpan(t, recorder)
+}
+
+func TestFiberMiddleware_RecoverPanics(t *testing.T) {
+	config, recorder := newTestConfig(t)
+	config.RecoverPanics = true
+	lgr := &recordingLogger{}
+	config.Logger = lgr
+
+	app := fiber.New()
+	app.Use(FiberMiddleware(config))
+	app.Get("/", func(c *fiber.Ctx) error {
+		panicking(nil, nil)
+		return nil
+	})
+	resp, err := app.Test(httptestRequest(http.MethodGet, "/"))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assertPanicSpan(t, recorder)
+	assert.Equal(t, []string{"HTTP handler panic"}, lgr.messages("error"))
+}
+
+// sentryTransport counts the events the Sentry client sends
+type sentryTransport struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (t *sentryTransport) Configure(sentry.ClientOptions) {}
+func (t *sentryTransport) SendEvent(event *sentry.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, event)
+}
+func (t *sentryTransport) Flush(time.Duration) bool              { return true }
+func (t *sentryTransport) FlushWithContext(context.Context) bool { return true }
+func (t *sentryTransport) Close()                                {}
+
+func TestMiddleware_PanicReportedToSentryOnce(t *testing.T) {
+	transport := &sentryTransport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{Dsn: "https://public@example.com/1", Transport: transport})
+	require.NoError(t, err)
+	sentry.CurrentHub().BindClient(client)
+	t.Cleanup(func() { sentry.CurrentHub().BindClient(nil) })
+
+	// the slog backend reports error logs to Sentry too
+	lgr, err := defaultlogger.New(logconfig.LogEnvDev, defaultlogger.Config{Level: "error"})
+	require.NoError(t, err)
+	config, _ := newTestConfig(t)
+	config.RecoverPanics = true
+	config.Logger = lgr
+
+	Middleware(config)(http.HandlerFunc(panicking)).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	require.Len(t, transport.events, 1, "the panic is reported once")
+	assert.Equal(t, sentry.LevelFatal, transport.events[0].Level)
+}
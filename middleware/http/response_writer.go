@@ -2,6 +2,7 @@ package http
 
 import (
 	"bufio"
+	"io"
 	"net"
 	"net/http"
 )
@@ -9,9 +10,13 @@ import (
 // responseWriter wraps http.ResponseWriter to capture status code and bytes written
 type responseWriter struct {
 	http.ResponseWriter
-	statusCode    int
-	bytesWritten  int
-	wroteHeader   bool
+	statusCode   int
+	bytesWritten int
+	wroteHeader  bool
+	// capture receives a copy of the body when body capture is enabled
+	capture io.Writer
+	// beforeWrite is run once before the headers are written
+	beforeWrite func()
 }
 
 // newResponseWriter creates a new response writer wrapper
@@ -25,6 +30,7 @@ func newResponseWriter(w http.ResponseWriter) *responseWriter {
 // WriteHeader captures the status code
 func (rw *responseWriter) WriteHeader(statusCode int) {
 	if !rw.wroteHeader {
+		rw.runBeforeWrite()
 		rw.statusCode = statusCode
 		rw.wroteHeader = true
 		rw.ResponseWriter.WriteHeader(statusCode)
@@ -38,6 +44,9 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	}
 	n, err := rw.ResponseWriter.Write(b)
 	rw.bytesWritten += n
+	if rw.capture != nil {
+		rw.capture.Write(b[:n])
+	}
 	return n, err
 }
 
@@ -51,6 +60,10 @@ func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 
 // Flush implements http.Flusher interface
 func (rw *responseWriter) Flush() {
+	// flushing writes the headers
+	if !rw.wroteHeader {
+		rw.runBeforeWrite()
+	}
 	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
 		flusher.Flush()
 	}
@@ -65,3 +78,20 @@ func (rw *responseWriter) Status() int {
 func (rw *responseWriter) BytesWritten() int {
 	return rw.bytesWritten
 }
+
+// teeBody copies the body written from now on to w
+func (rw *responseWriter) teeBody(w io.Writer) {
+	rw.capture = w
+}
+
+// onWriteHeader registers hook to run once before the headers are written
+func (rw *responseWriter) onWriteHeader(hook func()) {
+	rw.beforeWrite = hook
+}
+
+func (rw *responseWriter) runBeforeWrite() {
+	if hook := rw.beforeWrite; hook != nil {
+		rw.beforeWrite = nil
+		hook()
+	}
+}
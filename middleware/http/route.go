@@ -0,0 +1,113 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/mux"
+)
+
+// RouteResolver returns the route template matched for a request (e.g. "/users/{id}"),
+// or "" when unknown. It is called after the handler ran, once routers recorded their match.
+type RouteResolver func(r *http.Request) string
+
+// ServeMuxRoute resolves the pattern matched by a Go 1.22+ http.ServeMux, without its method and host
+func ServeMuxRoute(r *http.Request) string {
+	pattern := r.Pattern
+	if pattern == "" {
+		return ""
+	}
+	// patterns are "[METHOD ][HOST]/[PATH]", the host never contains a slash
+	if i := strings.Index(pattern, "/"); i >= 0 {
+		return pattern[i:]
+	}
+	return ""
+}
+
+// ChiRoute resolves the chi route pattern. The middleware must be registered with the chi
+// router's Use so the routing context is shared with the request.
+func ChiRoute(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		return rctx.RoutePattern()
+	}
+	return ""
+}
+
+// GorillaRoute resolves the gorilla/mux path template. The middleware must be registered with
+// the gorilla router's Use so the matched route is available on the request.
+func GorillaRoute(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ""
+	}
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+	return template
+}
+
+// resolveRoute returns the route for a request: the configured resolver then the ServeMux
+// pattern. It is empty when no router pattern matched, spans are then named after the
+// normalized path.
+func (c *Config) resolveRoute(r *http.Request) string {
+	if c.RouteResolver != nil {
+		if route := c.RouteResolver(r); route != "" {
+			return route
+		}
+	}
+	return ServeMuxRoute(r)
+}
+
+// NormalizePath replaces path segments that look like identifiers (numbers and UUIDs)
+// with "{id}", e.g. "/users/123/orders/8f14e45f-ceea-4b6f-9d2a-1b3c5d7e9f00" becomes
+// "/users/{id}/orders/{id}"
+func NormalizePath(path string) string {
+	if !strings.ContainsAny(path, "0123456789") {
+		return path
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if isNumeric(segment) || isUUID(segment) {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isUUID reports whether s has the canonical 8-4-4-4-12 hexadecimal form
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			if !isHex(s[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
@@ -0,0 +1,103 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestConfig(t *testing.T) (*Config, *tracetest.SpanRecorder) {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { _ = tp.Shutdown(t.Context()) })
+
+	config := DefaultConfig()
+	config.TracerProvider = tp
+	return config, recorder
+}
+
+func serve(t *testing.T, handler http.Handler, target string) {
+	t.Helper()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+}
+
+func spanRoute(t *testing.T, recorder *tracetest.SpanRecorder) (string, string) {
+	t.Helper()
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	for _, attr := range spans[0].Attributes() {
+		if attr.Key == "http.route" {
+			return spans[0].Name(), attr.Value.AsString()
+		}
+	}
+	return spans[0].Name(), ""
+}
+
+func ok(w http.ResponseWriter, r *http.Request) {}
+
+func TestMiddleware_ServeMuxPattern(t *testing.T) {
+	config, recorder := newTestConfig(t)
+	m := http.NewServeMux()
+	m.HandleFunc("GET /users/{id}", ok)
+
+	serve(t, Middleware(config)(m), "/users/42")
+
+	name, route := spanRoute(t, recorder)
+	assert.Equal(t, "GET /users/{id}", name)
+	assert.Equal(t, "/users/{id}", route)
+}
+
+func TestMiddleware_ChiRoute(t *testing.T) {
+	config, recorder := newTestConfig(t)
+	config.RouteResolver = ChiRoute
+	r := chi.NewRouter()
+	r.Use(Middleware(config))
+	r.Get("/orders/{orderID}/items", ok)
+
+	serve(t, r, "/orders/abc/items")
+
+	name, route := spanRoute(t, recorder)
+	assert.Equal(t, "GET /orders/{orderID}/items", name)
+	assert.Equal(t, "/orders/{orderID}/items", route)
+}
+
+func TestMiddleware_GorillaRoute(t *testing.T) {
+	config, recorder := newTestConfig(t)
+	config.RouteResolver = GorillaRoute
+	r := mux.NewRouter()
+	r.Use(Middleware(config))
+	r.HandleFunc("/articles/{slug}", ok)
+
+	serve(t, r, "/articles/hello-world")
+
+	name, _ := spanRoute(t, recorder)
+	assert.Equal(t, "GET /articles/{slug}", name)
+}
+
+func TestMiddleware_FallbackNormalizesIDs(t *testing.T) {
+	config, recorder := newTestConfig(t)
+
+	serve(t, Middleware(config)(http.HandlerFunc(ok)), "/users/123/files/8f14e45f-ceea-4b6f-9d2a-1b3c5d7e9f00")
+
+	name, route := spanRoute(t, recorder)
+	assert.Equal(t, "GET /users/{id}/files/{id}", name)
+	assert.Equal(t, "/users/{id}/files/{id}", route)
+}
+
+func TestNormalizePath(t *testing.T) {
+	assert.Equal(t, "/health", NormalizePath("/health"))
+	assert.Equal(t, "/v2/users/{id}", NormalizePath("/v2/users/7"))
+	assert.Equal(t, "/users/me", NormalizePath("/users/me"))
+}
+
+func httptestRequest(method, target string) *http.Request {
+	return httptest.NewRequest(method, target, nil)
+}
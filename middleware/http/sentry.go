@@ -0,0 +1,52 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/getsentry/sentry-go"
+	obssentry "github.com/ubin/go-observability/telemetry/provider/sentry"
+)
+
+// sentryScope is the per-request Sentry hub bound to the request context
+type sentryScope struct {
+	hub         *sentry.Hub
+	transaction atomic.Value
+}
+
+// startSentryScope clones the current hub for the request and attaches the request data,
+// the user returned by config.SentryUser and the transaction name to its scope.
+// It returns nil and the unchanged context when Sentry is not initialized.
+func startSentryScope(ctx context.Context, config *Config, r *http.Request, transaction string) (context.Context, *sentryScope) {
+	if !obssentry.Enabled() {
+		return ctx, nil
+	}
+
+	s := &sentryScope{hub: sentry.CurrentHub().Clone()}
+	s.transaction.Store(transaction)
+
+	scope := s.hub.Scope()
+	scope.SetRequest(r)
+	if config.SentryUser != nil {
+		if user := config.SentryUser(r); user != nil {
+			scope.SetUser(*user)
+		}
+	}
+	// the route is only known once the router ran, so the name is resolved when the event is sent
+	scope.AddEventProcessor(func(event *sentry.Event, _ *sentry.EventHint) *sentry.Event {
+		if event.Transaction == "" {
+			event.Transaction = s.transaction.Load().(string)
+		}
+		return event
+	})
+
+	return sentry.SetHubOnContext(ctx, s.hub), s
+}
+
+// setTransaction updates the transaction name, e.g. once the matched route is known
+func (s *sentryScope) setTransaction(transaction string) {
+	if s != nil {
+		s.transaction.Store(transaction)
+	}
+}
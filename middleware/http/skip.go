@@ -0,0 +1,96 @@
+package http
+
+import (
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// SkipMode is what a matching SkipRule disables
+type SkipMode int
+
+const (
+	// SkipAll bypasses the middleware: no span, metrics, headers or logs
+	SkipAll SkipMode = iota
+	// SkipLogsOnly traces and measures the request but does not log it, panics are still logged
+	SkipLogsOnly
+)
+
+// SkipRule matches requests to exclude. Every condition set must match, a rule without
+// conditions matches nothing. Rules are evaluated in order after SkipPaths.
+type SkipRule struct {
+	// Prefix matches paths starting with it, e.g. "/metrics/"
+	Prefix string
+
+	// Glob matches the path with path.Match, e.g. "/health/*" ("*" does not cross "/")
+	Glob string
+
+	// Regex matches the path, e.g. regexp.MustCompile(`^/static/.+\.(js|css)$`)
+	Regex *regexp.Regexp
+
+	// Methods match the request method, case-insensitively, e.g. "OPTIONS"
+	Methods []string
+
+	// UserAgent matches user agents containing it, case-insensitively, e.g. "kube-probe"
+	UserAgent string
+
+	// Match is a custom predicate. Fiber requests are converted to *http.Request for it.
+	Match func(r *http.Request) bool
+
+	// Mode is what the rule disables (defaults to SkipAll)
+	Mode SkipMode
+}
+
+// matches reports whether every condition of the rule matches the request
+func (r *SkipRule) matches(req requestInfo) bool {
+	if r.Prefix == "" && r.Glob == "" && r.Regex == nil && len(r.Methods) == 0 && r.UserAgent == "" && r.Match == nil {
+		return false
+	}
+	if r.Prefix != "" && !strings.HasPrefix(req.path, r.Prefix) {
+		return false
+	}
+	if r.Glob != "" {
+		if ok, _ := path.Match(r.Glob, req.path); !ok {
+			return false
+		}
+	}
+	if r.Regex != nil && !r.Regex.MatchString(req.path) {
+		return false
+	}
+	if len(r.Methods) > 0 && !matchesMethod(r.Methods, req.method) {
+		return false
+	}
+	if r.UserAgent != "" && !strings.Contains(strings.ToLower(req.userAgent), strings.ToLower(r.UserAgent)) {
+		return false
+	}
+	if r.Match != nil {
+		hr := req.httpRequest()
+		if hr == nil || !r.Match(hr) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSkip returns the mode of the first SkipPaths entry or SkipRule matching the request
+func (c *Config) matchSkip(req requestInfo) (SkipMode, bool) {
+	if c.shouldSkipPath(req.path) {
+		return SkipAll, true
+	}
+	for i := range c.SkipRules {
+		if c.SkipRules[i].matches(req) {
+			return c.SkipRules[i].Mode, true
+		}
+	}
+	return SkipAll, false
+}
@@ -0,0 +1,60 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_MatchSkip(t *testing.T) {
+	config := DefaultConfig()
+	config.SkipPaths = []string{"/health"}
+	config.SkipRules = []SkipRule{
+		{Prefix: "/metrics/"},
+		{Glob: "/health/*"},
+		{Regex: regexp.MustCompile(`^/static/.+\.(js|css)$`)},
+		{Methods: []string{"options"}},
+		{UserAgent: "kube-probe"},
+		{Match: func(r *http.Request) bool { return r.Header.Get("X-Synthetic") == "1" }},
+		{Prefix: "/debug/", Methods: []string{http.MethodGet}, Mode: SkipLogsOnly},
+		{},
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		target  string
+		header  http.Header
+		mode    SkipMode
+		skipped bool
+	}{
+		{name: "exact path", method: http.MethodGet, target: "/health", skipped: true},
+		{name: "prefix", method: http.MethodGet, target: "/metrics/cpu", skipped: true},
+		{name: "glob", method: http.MethodGet, target: "/health/live", skipped: true},
+		{name: "glob does not cross segments", method: http.MethodGet, target: "/health/live/deep"},
+		{name: "regex", method: http.MethodGet, target: "/static/js/app.js", skipped: true},
+		{name: "regex mismatch", method: http.MethodGet, target: "/static/logo.png"},
+		{name: "method", method: http.MethodOptions, target: "/users", skipped: true},
+		{name: "user agent", method: http.MethodGet, target: "/users", header: http.Header{"User-Agent": {"Kube-Probe/1.29"}}, skipped: true},
+		{name: "predicate", method: http.MethodGet, target: "/users", header: http.Header{"X-Synthetic": {"1"}}, skipped: true},
+		{name: "logs only", method: http.MethodGet, target: "/debug/vars", mode: SkipLogsOnly, skipped: true},
+		{name: "all conditions must match", method: http.MethodPost, target: "/debug/vars"},
+		{name: "no match", method: http.MethodGet, target: "/users"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.target, nil)
+			for key, values := range tt.header {
+				r.Header[key] = values
+			}
+			mode, skipped := config.matchSkip(httpRequestInfo(r, r.Header.Set))
+			assert.Equal(t, tt.skipped, skipped)
+			if skipped {
+				assert.Equal(t, tt.mode, mode)
+			}
+		})
+	}
+}
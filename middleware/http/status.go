@@ -0,0 +1,48 @@
+package http
+
+import (
+	"context"
+	"errors"
+)
+
+// StatusClientClosedRequest is the non-standard status recorded, as nginx does, for requests
+// the handler gave up on because the client aborted them
+const StatusClientClosedRequest = 499
+
+// RequestStatus describes a completed request to a StatusClassifier
+type RequestStatus struct {
+	Method string
+	// Route is the matched route template, as recorded in http.route
+	Route string
+	// Status is the response status, StatusClientClosedRequest when the handler failed
+	// because the client aborted the request
+	Status int
+	// Err is the handler error, if any
+	Err error
+	// ClientAborted reports the client canceled the request, see Config.StatusClassifier
+	ClientAborted bool
+}
+
+// StatusClassifier reports whether a completed request failed
+type StatusClassifier func(s RequestStatus) bool
+
+// DefaultStatusClassifier follows the OpenTelemetry semantic conventions for server spans:
+// 5xx statuses are errors, 4xx statuses are the client's and leave the span status unset
+func DefaultStatusClassifier(s RequestStatus) bool {
+	return s.Status >= 500
+}
+
+// statusClassifier returns the configured classifier or DefaultStatusClassifier
+func (c *Config) statusClassifier() StatusClassifier {
+	if c.StatusClassifier != nil {
+		return c.StatusClassifier
+	}
+	return DefaultStatusClassifier
+}
+
+// clientAborted reports whether the client canceled the request: the request context was
+// canceled or the handler gave up with context.Canceled. Fiber does not cancel requests, only
+// handler errors are seen there.
+func clientAborted(ctx context.Context, err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled)
+}
@@ -0,0 +1,61 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	obserrors "github.com/ubin/go-observability/errors"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestDefaultStatusClassifier(t *testing.T) {
+	for status, want := range map[int]bool{
+		http.StatusOK:                  false,
+		http.StatusNotFound:            false,
+		http.StatusTooManyRequests:     false,
+		StatusClientClosedRequest:      false,
+		http.StatusInternalServerError: true,
+		http.StatusServiceUnavailable:  true,
+	} {
+		assert.Equal(t, want, DefaultStatusClassifier(RequestStatus{Status: status}), "status %d", status)
+	}
+}
+
+func TestMiddleware_UserErrorLeavesSpanStatusUnset(t *testing.T) {
+	config, recorder := newTestConfig(t)
+	handler := Middleware(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteError(w, r, obserrors.New("invalid email").WithKind(obserrors.KindUser))
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/signup", nil))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	require.Len(t, spans[0].Events(), 1, "the handler error is recorded")
+}
+
+func TestMiddleware_ClientDisconnected(t *testing.T) {
+	config, recorder := newTestConfig(t)
+	handler := Middleware(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		WriteError(w, r, r.Context().Err())
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/report", nil).WithContext(ctx))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	attrs := spanAttrs(spans[0])
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, int64(StatusClientClosedRequest), attrs["http.status_code"].AsInt64())
+	assert.True(t, attrs["http.client_aborted"].AsBool())
+}
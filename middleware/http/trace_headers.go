@@ -0,0 +1,96 @@
+package http
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ubin/go-observability/middleware/httpobs"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceResponseHeader is the W3C Trace Context header carrying the server span to the client
+const TraceResponseHeader = "traceresponse"
+
+// ServerTimingHeader reports the request timings to the client, e.g. browser dev tools
+const ServerTimingHeader = "Server-Timing"
+
+// TraceHeadersConfig selects the trace context headers set on responses
+type TraceHeadersConfig struct {
+	// TraceID and SpanID rename the trace and span ID headers, empty keeps X-Trace-ID and X-Span-ID
+	TraceID string
+	SpanID  string
+
+	// DisableIDs leaves the trace and span ID headers out, e.g. so public endpoints do not
+	// expose trace IDs
+	DisableIDs bool
+
+	// TraceResponse sets the W3C traceresponse header, e.g.
+	// traceresponse: 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+	TraceResponse bool
+}
+
+// traceHeaders returns the configured trace headers, empty names replaced by the default ones
+func (c *Config) traceHeaders() TraceHeadersConfig {
+	var headers TraceHeadersConfig
+	if c.TraceHeaders != nil {
+		headers = *c.TraceHeaders
+	}
+	if headers.TraceID == "" {
+		headers.TraceID = TraceIDHeader
+	}
+	if headers.SpanID == "" {
+		headers.SpanID = SpanIDHeader
+	}
+	return headers
+}
+
+// setTraceHeaders sets the trace context headers of the server span
+func (f *inflight) setTraceHeaders(spanContext trace.SpanContext) {
+	headers := f.o.config.traceHeaders()
+	if !headers.DisableIDs {
+		f.req.setHeader(headers.TraceID, f.traceID)
+		f.req.setHeader(headers.SpanID, f.spanID)
+	}
+	if headers.TraceResponse {
+		f.req.setHeader(TraceResponseHeader, "00-"+f.traceID+"-"+f.spanID+"-"+spanContext.TraceFlags().String())
+	}
+}
+
+// setServerTiming sets the Server-Timing header once, just before the response headers are
+// written: total is the time spent until then, followed by the phases recorded by the handler
+func (f *inflight) setServerTiming() {
+	if f.timings == nil || f.serverTimingSet {
+		return
+	}
+	f.serverTimingSet = true
+	f.req.setHeader(ServerTimingHeader, formatServerTiming(time.Since(f.startTime), f.timings.All()))
+}
+
+// formatServerTiming formats the Server-Timing header value, durations are in milliseconds
+func formatServerTiming(total time.Duration, timings []httpobs.Timing) string {
+	var b strings.Builder
+	b.WriteString("total;dur=")
+	b.WriteString(formatMilliseconds(total))
+	for _, timing := range timings {
+		b.WriteString(", ")
+		b.WriteString(serverTimingName(timing.Name))
+		b.WriteString(";dur=")
+		b.WriteString(formatMilliseconds(timing.Duration))
+	}
+	return b.String()
+}
+
+func formatMilliseconds(d time.Duration) string {
+	return strconv.FormatFloat(float64(d.Round(time.Microsecond))/float64(time.Millisecond), 'f', -1, 64)
+}
+
+// serverTimingName replaces the characters not allowed in a header token with "_"
+func serverTimingName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r > 0x7e || r <= ' ' || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+}
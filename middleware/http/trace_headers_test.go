@@ -0,0 +1,66 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ubin/go-observability/middleware/httpobs"
+)
+
+func TestFormatServerTiming(t *testing.T) {
+	assert.Equal(t, "total;dur=12.5, db;dur=3, cache_hit_;dur=0.001",
+		formatServerTiming(12500*time.Microsecond, []httpobs.Timing{
+			{Name: "db", Duration: 3 * time.Millisecond},
+			{Name: "cache hit;", Duration: 1200 * time.Nanosecond},
+		}))
+}
+
+func TestTraceHeaders_Defaults(t *testing.T) {
+	tests := []struct {
+		name            string
+		headers         *TraceHeadersConfig
+		traceID, spanID string
+		traceResponse   bool
+	}{
+		{name: "nil", traceID: TraceIDHeader, spanID: SpanIDHeader},
+		{name: "traceresponse keeps the IDs", headers: &TraceHeadersConfig{TraceResponse: true},
+			traceID: TraceIDHeader, spanID: SpanIDHeader, traceResponse: true},
+		{name: "renamed", headers: &TraceHeadersConfig{TraceID: "Trace-Id"}, traceID: "Trace-Id", spanID: SpanIDHeader},
+		{name: "disabled", headers: &TraceHeadersConfig{DisableIDs: true, TraceResponse: true}, traceResponse: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, _ := newTestConfig(t)
+			config.TraceHeaders = tt.headers
+			w := httptest.NewRecorder()
+			Middleware(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).
+				ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			header := w.Result().Header
+			for _, name := range []string{TraceIDHeader, SpanIDHeader, "Trace-Id"} {
+				assert.Equal(t, name == tt.traceID || name == tt.spanID, header.Get(name) != "", name)
+			}
+			assert.Equal(t, tt.traceResponse, header.Get(TraceResponseHeader) != "")
+		})
+	}
+}
+
+func TestServerTiming_StreamedResponse(t *testing.T) {
+	config, _ := newTestConfig(t)
+	config.ServerTiming = true
+
+	handler := Middleware(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httpobs.RecordTiming(r.Context(), "auth", time.Millisecond)
+		w.(http.Flusher).Flush()
+		// recorded once the headers were sent
+		httpobs.RecordTiming(r.Context(), "render", time.Millisecond)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Regexp(t, `^total;dur=[0-9.]+, auth;dur=1$`, w.Result().Header.Get(ServerTimingHeader))
+}
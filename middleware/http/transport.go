@@ -0,0 +1,176 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// Transport returns an http.RoundTripper instrumenting outgoing requests made through base
+// (http.DefaultTransport when nil). It creates client spans, injects the trace context with
+// the configured propagator, records the client metrics and logs failed calls.
+// The span ends once the response body is read to EOF or closed.
+func Transport(base http.RoundTripper, config *Config) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	var tracer trace.Tracer = noop.NewTracerProvider().Tracer("")
+	if tp := config.tracerProvider(); tp != nil {
+		tracer = tp.Tracer(config.ServiceName)
+	}
+
+	return &transport{
+		base:    base,
+		config:  config,
+		tracer:  tracer,
+		metrics: newClientMetrics(config),
+	}
+}
+
+// transport is the RoundTripper returned by Transport
+type transport struct {
+	base    http.RoundTripper
+	config  *Config
+	tracer  trace.Tracer
+	metrics *requestMetrics
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	startTime := time.Now()
+	ctx := r.Context()
+	host := r.URL.Host
+	// user info may contain credentials, keep it out of spans and logs
+	url := r.URL.Redacted()
+
+	metricAttrs := clientAttrs(r.Method, r.URL)
+	activeAttrs := t.metrics.start(ctx, metricAttrs...)
+
+	ctx, span := t.tracer.Start(ctx, fmt.Sprintf("HTTP %s", r.Method),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.method", r.Method),
+			attribute.String("http.url", url),
+			attribute.String("http.host", host),
+			attribute.String("http.scheme", r.URL.Scheme),
+		),
+	)
+
+	// RoundTrippers must not modify the caller's request, inject the headers into a copy
+	r = r.Clone(ctx)
+	t.config.propagator().Inject(ctx, propagation.HeaderCarrier(r.Header))
+
+	resp, err := t.base.RoundTrip(r)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.SetAttributes(attribute.Bool("error", true))
+		span.End()
+
+		t.metrics.end(ctx, activeAttrs, metricAttrs, 0, time.Since(startTime), r.ContentLength, 0)
+		if t.config.Logger != nil {
+			t.config.Logger.ErrorContext(ctx, "HTTP client request failed",
+				"method", r.Method,
+				"url", url,
+				"duration_ms", time.Since(startTime).Milliseconds(),
+				"error", err)
+		}
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+	// unlike server spans, client spans are errors for 4xx statuses too: the call failed
+	if resp.StatusCode >= 400 {
+		span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", resp.StatusCode))
+		span.SetAttributes(attribute.Bool("error", true))
+	}
+	if resp.StatusCode >= 500 {
+		if t.config.Logger != nil {
+			t.config.Logger.WarnContext(ctx, "HTTP client request returned a server error",
+				"method", r.Method,
+				"url", url,
+				"status", resp.StatusCode,
+				"duration_ms", time.Since(startTime).Milliseconds())
+		}
+	}
+
+	finish := func(bytesRead int64) {
+		span.SetAttributes(attribute.Int64("http.response_size", bytesRead))
+		span.End()
+		t.metrics.end(ctx, activeAttrs, metricAttrs, resp.StatusCode,
+			time.Since(startTime), r.ContentLength, bytesRead)
+	}
+	if resp.Body == nil || resp.Body == http.NoBody {
+		finish(0)
+		return resp, nil
+	}
+	resp.Body = &trackedBody{ReadCloser: resp.Body, finish: finish}
+	return resp, nil
+}
+
+// clientAttrs labels the client request metrics. server.port defaults to the scheme's port
+// when the URL has none.
+func clientAttrs(method string, u *url.URL) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.String("http.request.method", metricMethod(method)),
+		attribute.String("server.address", u.Hostname()),
+	}
+	if port := serverPort(u); port != 0 {
+		attrs = append(attrs, attribute.Int("server.port", port))
+	}
+	return attrs
+}
+
+// serverPort returns the port of u, the default port of its scheme, or 0 when unknown
+func serverPort(u *url.URL) int {
+	if port, err := strconv.Atoi(u.Port()); err == nil {
+		return port
+	}
+	switch u.Scheme {
+	case "http":
+		return 80
+	case "https":
+		return 443
+	}
+	return 0
+}
+
+// trackedBody counts the response bytes read and calls finish once, on EOF, error or Close
+type trackedBody struct {
+	io.ReadCloser
+	finish func(bytesRead int64)
+
+	read int64
+	once sync.Once
+}
+
+// Read implements io.Reader
+func (b *trackedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	if err != nil {
+		b.once.Do(func() { b.finish(b.read) })
+	}
+	return n, err
+}
+
+// Close implements io.Closer
+func (b *trackedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.finish(b.read) })
+	return err
+}
@@ -0,0 +1,205 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// logEntry is a message logged through recordingLogger
+type logEntry struct {
+	level   string
+	msg     string
+	keyvals []interface{}
+}
+
+// recordingLogger keeps the logged messages, it is safe for concurrent use
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries []logEntry
+}
+
+func (l *recordingLogger) record(level, msg string, keyvals []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, logEntry{level: level, msg: msg, keyvals: keyvals})
+}
+
+// messages returns the messages logged at level
+func (l *recordingLogger) messages(level string) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var msgs []string
+	for _, entry := range l.entries {
+		if entry.level == level {
+			msgs = append(msgs, entry.msg)
+		}
+	}
+	return msgs
+}
+
+func (l *recordingLogger) InfoContext(ctx context.Context, msg string, keyvals ...interface{}) {
+	l.record("info", msg, keyvals)
+}
+
+func (l *recordingLogger) WarnContext(ctx context.Context, msg string, keyvals ...interface{}) {
+	l.record("warn", msg, keyvals)
+}
+
+func (l *recordingLogger) ErrorContext(ctx context.Context, msg string, keyvals ...interface{}) {
+	l.record("error", msg, keyvals)
+}
+
+func (l *recordingLogger) DebugContext(ctx context.Context, msg string, keyvals ...interface{}) {
+	l.record("debug", msg, keyvals)
+}
+
+func (l *recordingLogger) PanicContext(ctx context.Context, msg string, keyvals ...interface{}) {
+	l.record("panic", msg, keyvals)
+}
+
+func TestTransport_PropagatesAndRecords(t *testing.T) {
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	config, recorder := newTestConfig(t)
+	config.Propagator = propagation.TraceContext{}
+	reader := sdkmetric.NewManualReader()
+	config.MeterProvider = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	client := &http.Client{Transport: Transport(nil, config)}
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/greeting", nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, "hello", string(body))
+	assert.Empty(t, req.Header.Get("traceparent"), "caller request must not be modified")
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "HTTP GET", span.Name())
+	assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+	assert.Contains(t, traceparent, span.SpanContext().TraceID().String())
+	assert.Contains(t, traceparent, span.SpanContext().SpanID().String())
+
+	attrs := make(map[string]any)
+	for _, attr := range span.Attributes() {
+		attrs[string(attr.Key)] = attr.Value.AsInterface()
+	}
+	assert.Equal(t, int64(http.StatusOK), attrs["http.status_code"])
+	assert.Equal(t, int64(len("hello")), attrs["http.response_size"])
+
+	metrics := collect(t, reader)
+	duration, ok := metrics["http.client.request.duration"].(metricdata.Histogram[float64])
+	require.True(t, ok, "client duration histogram should be recorded")
+	require.Len(t, duration.DataPoints, 1)
+	class, _ := duration.DataPoints[0].Attributes.Value("http.response.status_class")
+	assert.Equal(t, "2xx", class.AsString())
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	address, _ := duration.DataPoints[0].Attributes.Value("server.address")
+	assert.Equal(t, "127.0.0.1", address.AsString())
+	port, _ := duration.DataPoints[0].Attributes.Value("server.port")
+	assert.Equal(t, serverURL.Port(), strconv.FormatInt(port.AsInt64(), 10))
+	responseSize, ok := metrics["http.client.response.body.size"].(metricdata.Histogram[int64])
+	require.True(t, ok, "client response size histogram should be recorded")
+	assert.Equal(t, int64(len("hello")), responseSize.DataPoints[0].Sum)
+}
+
+func TestTransport_ErrorStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		warns  int
+	}{
+		{status: http.StatusNotFound},
+		// only server errors are logged
+		{status: http.StatusBadGateway, warns: 1},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			config, recorder := newTestConfig(t)
+			lgr := &recordingLogger{}
+			config.Logger = lgr
+
+			resp, err := (&http.Client{Transport: Transport(nil, config)}).Get(server.URL)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+			assert.Equal(t, codes.Error, spans[0].Status().Code)
+			assert.Len(t, lgr.messages("warn"), tt.warns)
+		})
+	}
+}
+
+func TestTransport_ConnectionError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(ok))
+	url := server.URL
+	server.Close()
+
+	config, recorder := newTestConfig(t)
+	lgr := &recordingLogger{}
+	config.Logger = lgr
+
+	_, err := (&http.Client{Transport: Transport(nil, config)}).Get(url)
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	require.Len(t, spans[0].Events(), 1, "the error should be recorded on the span")
+	assert.Equal(t, []string{"HTTP client request failed"}, lgr.messages("error"))
+}
+
+func TestClientAttrs_ServerAddressAndPort(t *testing.T) {
+	tests := []struct {
+		url     string
+		address string
+		port    int64
+	}{
+		{url: "http://api.example.com/users", address: "api.example.com", port: 80},
+		{url: "https://api.example.com/users", address: "api.example.com", port: 443},
+		{url: "https://api.example.com:8443/users", address: "api.example.com", port: 8443},
+		{url: "http://[::1]:8080/", address: "::1", port: 8080},
+		{url: "unix://socket", address: "socket"},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse(tt.url)
+		require.NoError(t, err)
+		attrs := attribute.NewSet(clientAttrs(http.MethodGet, u)...)
+
+		address, _ := attrs.Value("server.address")
+		assert.Equal(t, tt.address, address.AsString(), tt.url)
+		port, ok := attrs.Value("server.port")
+		assert.Equal(t, tt.port != 0, ok, tt.url)
+		assert.Equal(t, tt.port, port.AsInt64(), tt.url)
+	}
+}
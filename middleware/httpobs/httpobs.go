@@ -0,0 +1,66 @@
+// Package httpobs lets handlers add fields to the log line the HTTP middleware writes when
+// a request completes, and phase timings to its Server-Timing header. It has no dependencies
+// so any package handling requests can import it.
+package httpobs
+
+import (
+	"context"
+	"sync"
+)
+
+type annotationsKey struct{}
+
+// Annotations are the fields accumulated for a request, safe for concurrent use
+type Annotations struct {
+	mu      sync.Mutex
+	keyvals []any
+}
+
+// NewContext returns a context collecting annotations, called by the middleware for each request
+func NewContext(ctx context.Context) (context.Context, *Annotations) {
+	a := &Annotations{}
+	return context.WithValue(ctx, annotationsKey{}, a), a
+}
+
+// FromContext returns the annotations of the request, or nil outside an instrumented request
+func FromContext(ctx context.Context) *Annotations {
+	a, _ := ctx.Value(annotationsKey{}).(*Annotations)
+	return a
+}
+
+// Annotate adds key-value pairs to the request log line, e.g. Annotate(ctx, "cart_items", 3).
+// Annotating a key again replaces its value. It does nothing outside an instrumented request.
+func Annotate(ctx context.Context, keyvals ...any) {
+	if a := FromContext(ctx); a != nil {
+		a.Add(keyvals...)
+	}
+}
+
+// Add adds key-value pairs, a trailing key without value is ignored
+func (a *Annotations) Add(keyvals ...any) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		a.set(keyvals[i], keyvals[i+1])
+	}
+}
+
+func (a *Annotations) set(key, value any) {
+	for i := 0; i < len(a.keyvals); i += 2 {
+		if a.keyvals[i] == key {
+			a.keyvals[i+1] = value
+			return
+		}
+	}
+	a.keyvals = append(a.keyvals, key, value)
+}
+
+// Fields returns a copy of the key-value pairs in the order they were first added
+func (a *Annotations) Fields() []any {
+	if a == nil {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]any(nil), a.keyvals...)
+}
@@ -0,0 +1,62 @@
+package httpobs
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnotate(t *testing.T) {
+	ctx, annotations := NewContext(context.Background())
+
+	Annotate(ctx, "cart_items", 3, "coupon", "SPRING")
+	Annotate(ctx, "cart_items", 4, "dangling")
+
+	assert.Equal(t, []any{"cart_items", 4, "coupon", "SPRING"}, annotations.Fields())
+	assert.Same(t, annotations, FromContext(ctx))
+}
+
+func TestAnnotate_OutsideRequest(t *testing.T) {
+	assert.NotPanics(t, func() { Annotate(context.Background(), "key", "value") })
+	assert.Nil(t, FromContext(context.Background()).Fields())
+}
+
+func TestAnnotate_Concurrent(t *testing.T) {
+	ctx, annotations := NewContext(context.Background())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Annotate(ctx, "worker", i)
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, annotations.Fields(), 2)
+}
+
+func TestRecordTiming(t *testing.T) {
+	ctx, timings := NewTimingContext(context.Background())
+
+	RecordTiming(ctx, "db", 2*time.Millisecond)
+	RecordTiming(ctx, "render", time.Millisecond)
+	RecordTiming(ctx, "db", 3*time.Millisecond)
+	StartTiming(ctx, "cache")()
+
+	all := timings.All()
+	assert.Len(t, all, 3)
+	assert.Equal(t, Timing{Name: "db", Duration: 5 * time.Millisecond}, all[0])
+	assert.Equal(t, Timing{Name: "render", Duration: time.Millisecond}, all[1])
+	assert.Equal(t, "cache", all[2].Name)
+	assert.Same(t, timings, TimingsFromContext(ctx))
+}
+
+func TestRecordTiming_OutsideRequest(t *testing.T) {
+	assert.NotPanics(t, func() { StartTiming(context.Background(), "db")() })
+	assert.Nil(t, TimingsFromContext(context.Background()).All())
+}
@@ -0,0 +1,73 @@
+package httpobs
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type timingsKey struct{}
+
+// Timing is the duration of a phase of a request, e.g. "db" or "render"
+type Timing struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Timings are the phases recorded for a request, safe for concurrent use
+type Timings struct {
+	mu      sync.Mutex
+	timings []Timing
+}
+
+// NewTimingContext returns a context collecting timings, called by the middleware for each request
+func NewTimingContext(ctx context.Context) (context.Context, *Timings) {
+	t := &Timings{}
+	return context.WithValue(ctx, timingsKey{}, t), t
+}
+
+// TimingsFromContext returns the timings of the request, or nil outside an instrumented request
+func TimingsFromContext(ctx context.Context) *Timings {
+	t, _ := ctx.Value(timingsKey{}).(*Timings)
+	return t
+}
+
+// RecordTiming adds the duration of a phase to the request Server-Timing header.
+// Recording a phase again adds to its duration. It does nothing outside an instrumented request.
+func RecordTiming(ctx context.Context, name string, d time.Duration) {
+	if t := TimingsFromContext(ctx); t != nil {
+		t.Add(name, d)
+	}
+}
+
+// StartTiming starts timing a phase and returns the func recording it, e.g.
+// defer httpobs.StartTiming(ctx, "db")()
+func StartTiming(ctx context.Context, name string) func() {
+	start := time.Now()
+	return func() {
+		RecordTiming(ctx, name, time.Since(start))
+	}
+}
+
+// Add adds d to the duration of the named phase
+func (t *Timings) Add(name string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i := range t.timings {
+		if t.timings[i].Name == name {
+			t.timings[i].Duration += d
+			return
+		}
+	}
+	t.timings = append(t.timings, Timing{Name: name, Duration: d})
+}
+
+// All returns a copy of the timings in the order they were first recorded
+func (t *Timings) All() []Timing {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Timing(nil), t.timings...)
+}
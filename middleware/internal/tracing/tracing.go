@@ -0,0 +1,19 @@
+// Package tracing holds the tracer provider handling shared by the HTTP and gRPC middleware.
+package tracing
+
+import (
+	"reflect"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Enabled reports whether tp traces. A nil provider disables tracing, as does a typed nil
+// such as the *sdktrace.TracerProvider returned by a failed telemetry.InitTracer, which
+// would panic on use.
+func Enabled(tp trace.TracerProvider) bool {
+	if tp == nil {
+		return false
+	}
+	v := reflect.ValueOf(tp)
+	return v.Kind() != reflect.Pointer || !v.IsNil()
+}
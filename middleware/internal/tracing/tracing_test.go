@@ -0,0 +1,18 @@
+package tracing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestEnabled(t *testing.T) {
+	var failed *sdktrace.TracerProvider
+
+	assert.False(t, Enabled(nil))
+	assert.False(t, Enabled(failed), "typed nil")
+	assert.True(t, Enabled(sdktrace.NewTracerProvider()))
+	assert.True(t, Enabled(noop.NewTracerProvider()), "value providers")
+}
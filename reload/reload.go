@@ -0,0 +1,308 @@
+// Package reload applies logging and tracing configuration changes without restarting the
+// process, on SIGHUP or when the configuration file changes.
+package reload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ubin/go-observability/logger"
+	"github.com/ubin/go-observability/logger/loggerfactory"
+	"github.com/ubin/go-observability/telemetry"
+	"github.com/ubin/go-observability/telemetry/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	// DefaultPollInterval is how often the watched file is checked for changes
+	DefaultPollInterval = 2 * time.Second
+	// DefaultDrainTimeout bounds the flush of the previous tracer provider after a swap
+	DefaultDrainTimeout = 10 * time.Second
+)
+
+// Config is the configuration applied on every reload
+type Config struct {
+	// Env is passed to loggerfactory, "production" or "prod" selects the production format
+	Env string
+	// Logger configures logger.Log, nil leaves logging untouched
+	Logger logger.Config
+	// Tracing configures the tracer provider, nil leaves tracing untouched
+	Tracing config.Config
+}
+
+// Loader reads the current configuration, typically by re-reading the configuration file
+type Loader func() (Config, error)
+
+// Option customizes a Manager
+type Option func(*Manager)
+
+// WithFile reloads when the file modification time or size changes
+func WithFile(path string) Option {
+	return func(m *Manager) {
+		m.file = path
+	}
+}
+
+// WithPollInterval sets how often the watched file is checked (default DefaultPollInterval)
+func WithPollInterval(d time.Duration) Option {
+	return func(m *Manager) {
+		m.pollInterval = d
+	}
+}
+
+// WithSignals sets the signals triggering a reload (default SIGHUP), no signal disables them
+func WithSignals(signals ...os.Signal) Option {
+	return func(m *Manager) {
+		m.signals = signals
+	}
+}
+
+// WithDrainTimeout bounds the shutdown of the previous tracer provider (default DefaultDrainTimeout)
+func WithDrainTimeout(d time.Duration) Option {
+	return func(m *Manager) {
+		m.drainTimeout = d
+	}
+}
+
+// WithTracerOptions passes options to telemetry.NewTracerProvider on every rebuild
+func WithTracerOptions(opts ...telemetry.Option) Option {
+	return func(m *Manager) {
+		m.tracerOptions = append(m.tracerOptions, opts...)
+	}
+}
+
+// Manager owns the process-wide logger and tracer provider and rebuilds them when the
+// configuration changes. The logger is installed with logger.SetLogger and a tracer
+// provider that survives rebuilds is installed as the OpenTelemetry global.
+type Manager struct {
+	load          Loader
+	file          string
+	pollInterval  time.Duration
+	signals       []os.Signal
+	drainTimeout  time.Duration
+	tracerOptions []telemetry.Option
+
+	provider *tracerProvider
+
+	// mu serializes reloads and guards the fields below
+	mu          sync.Mutex
+	loggerCfg   logger.Config
+	env         string
+	tracingCfg  config.Config
+	shutdownOld func(ctx context.Context) error
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New creates a Manager, call Start to apply the initial configuration and watch for changes
+func New(load Loader, opts ...Option) *Manager {
+	m := &Manager{
+		load:         load,
+		pollInterval: DefaultPollInterval,
+		signals:      []os.Signal{syscall.SIGHUP},
+		drainTimeout: DefaultDrainTimeout,
+		provider:     newTracerProvider(),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// TracerProvider returns the tracer provider whose implementation follows reloads,
+// pass it to middlewares instead of the provider returned by telemetry.InitTracer
+func (m *Manager) TracerProvider() trace.TracerProvider {
+	return m.provider
+}
+
+// Start applies the initial configuration, installs the globals and starts watching for changes.
+// An invalid initial configuration is returned as an error and nothing is watched.
+func (m *Manager) Start(ctx context.Context) error {
+	// record the file version before loading it, so a change made meanwhile is not missed
+	var last fileState
+	if m.file != "" {
+		last, _ = statFile(m.file)
+	}
+
+	if err := m.Reload(ctx); err != nil {
+		return err
+	}
+
+	otel.SetTextMapPropagator(telemetry.Propagator())
+	otel.SetErrorHandler(logger.NewOtelErrorHandler(logger.DefaultOtelErrorInterval))
+	otel.SetTracerProvider(m.provider)
+
+	var sigCh chan os.Signal
+	if len(m.signals) > 0 {
+		sigCh = make(chan os.Signal, 1)
+		signal.Notify(sigCh, m.signals...)
+	}
+
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+	go m.watch(sigCh, last)
+	return nil
+}
+
+// Stop stops watching and shuts down the current tracer provider
+func (m *Manager) Stop(ctx context.Context) error {
+	if m.stop != nil {
+		close(m.stop)
+		<-m.done
+		m.stop = nil
+	}
+
+	m.mu.Lock()
+	m.provider.swap(noop.NewTracerProvider())
+	shutdown := m.shutdownOld
+	m.shutdownOld = nil
+	m.mu.Unlock()
+
+	if shutdown == nil {
+		return nil
+	}
+	return shutdown(ctx)
+}
+
+// Reload loads the configuration and applies what changed. Nothing is applied when the
+// configuration is invalid: the previous logger and tracer provider keep running.
+func (m *Manager) Reload(ctx context.Context) error {
+	shutdownPrevious, err := m.apply()
+	if err != nil {
+		return err
+	}
+	// spans started on the old provider end there, drain its batch processor before dropping
+	// it. This can take up to the drain timeout, the lock is released so reloads and Stop are
+	// not blocked meanwhile.
+	if shutdownPrevious != nil {
+		drainCtx, cancel := context.WithTimeout(ctx, m.drainTimeout)
+		defer cancel()
+		if err := shutdownPrevious(drainCtx); err != nil {
+			logger.L().Warn("draining previous tracer provider failed", "error", err)
+		}
+	}
+	return nil
+}
+
+// apply loads the configuration and swaps in what changed. It returns the shutdown of the
+// tracer provider that was replaced, if any, for the caller to drain outside the lock.
+func (m *Manager) apply() (func(ctx context.Context) error, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cfg, err := m.load()
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+
+	// build everything before swapping so a bad config does not leave a partial update
+	var newLogger logger.Logger
+	loggerChanged := cfg.Logger != nil && (cfg.Env != m.env || !reflect.DeepEqual(cfg.Logger, m.loggerCfg))
+	if loggerChanged {
+		if newLogger, err = loggerfactory.New(cfg.Logger, cfg.Env); err != nil {
+			return nil, err
+		}
+	}
+
+	var newProvider trace.TracerProvider
+	var shutdown func(ctx context.Context) error
+	tracingChanged := cfg.Tracing != nil && !reflect.DeepEqual(cfg.Tracing, m.tracingCfg)
+	if tracingChanged {
+		if newProvider, shutdown, err = m.buildTracerProvider(cfg.Tracing); err != nil {
+			return nil, err
+		}
+	}
+
+	if loggerChanged {
+		logger.SetLogger(newLogger)
+		m.loggerCfg, m.env = cfg.Logger, cfg.Env
+	}
+	var shutdownPrevious func(ctx context.Context) error
+	if tracingChanged {
+		m.provider.swap(newProvider)
+		m.tracingCfg = cfg.Tracing
+		shutdownPrevious, m.shutdownOld = m.shutdownOld, shutdown
+	}
+
+	if loggerChanged || tracingChanged {
+		logger.L().Info("observability config reloaded", "logger", loggerChanged, "tracing", tracingChanged)
+	}
+	return shutdownPrevious, nil
+}
+
+func (m *Manager) buildTracerProvider(cfg config.Config) (trace.TracerProvider, func(ctx context.Context) error, error) {
+	if !cfg.IsEnabled() {
+		return noop.NewTracerProvider(), nil, nil
+	}
+	tp, err := telemetry.NewTracerProvider(cfg, m.tracerOptions...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tp, tp.Shutdown, nil
+}
+
+// watch triggers reloads on signals and file changes until Stop is called
+func (m *Manager) watch(sigCh chan os.Signal, last fileState) {
+	defer close(m.done)
+	if sigCh != nil {
+		defer signal.Stop(sigCh)
+	}
+
+	var tick <-chan time.Time
+	if m.file != "" {
+		ticker := time.NewTicker(m.pollInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case sig := <-sigCh:
+			m.reload("signal", sig.String())
+		case <-tick:
+			state, err := statFile(m.file)
+			if err != nil || state == last {
+				// a file being replaced may be missing for a moment, keep the last state
+				continue
+			}
+			last = state
+			m.reload("file", m.file)
+		}
+	}
+}
+
+func (m *Manager) reload(trigger, source string) {
+	if err := m.Reload(context.Background()); err != nil {
+		logger.L().Error("observability config reload failed, keeping previous config",
+			"trigger", trigger, "source", source, "error", err)
+	}
+}
+
+// fileState identifies a version of the watched file
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+func statFile(path string) (fileState, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}, err
+	}
+	if info.IsDir() {
+		return fileState{}, errors.New("config path is a directory")
+	}
+	return fileState{modTime: info.ModTime(), size: info.Size()}, nil
+}
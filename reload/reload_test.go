@@ -0,0 +1,169 @@
+package reload
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ubin/go-observability/logger"
+	"github.com/ubin/go-observability/logger/loggerfactory/config"
+	"github.com/ubin/go-observability/logger/loggerfactory/defaultlogger"
+	tracingconfig "github.com/ubin/go-observability/telemetry/config"
+)
+
+// fileLoader reads "<log level> <tracing enabled> [exporter type]" from path, the exporter
+// defaults to stdout
+func fileLoader(path string) Loader {
+	return func() (Config, error) {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return Config{}, err
+		}
+		fields := strings.Fields(string(b))
+		if len(fields) < 2 || len(fields) > 3 {
+			return Config{}, fmt.Errorf("malformed config %q", b)
+		}
+		exporter := tracingconfig.ExporterTypeStdout
+		if len(fields) == 3 {
+			exporter = tracingconfig.ExporterType(fields[2])
+		}
+		return Config{
+			Logger: defaultlogger.Config{Code: config.SLOG, Level: fields[0]},
+			Tracing: &tracingconfig.TracingConfig{
+				ServiceName:  "reload-test",
+				Enabled:      fields[1] == "on",
+				ExporterType: exporter,
+			},
+		}, nil
+	}
+}
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func debugEnabled() bool {
+	e, ok := logger.L().(logger.LevelEnabler)
+	return ok && e.Enabled(context.Background(), -4)
+}
+
+func tracingEnabled(m *Manager) bool {
+	_, span := m.TracerProvider().Tracer("test").Start(context.Background(), "probe")
+	return span.IsRecording()
+}
+
+func TestManager_ReloadsOnFileChange(t *testing.T) {
+	prev := logger.L()
+	t.Cleanup(func() { logger.SetLogger(prev) })
+
+	path := filepath.Join(t.TempDir(), "config")
+	writeConfig(t, path, "info off")
+
+	m := New(fileLoader(path), WithFile(path), WithPollInterval(10*time.Millisecond), WithSignals())
+	require.NoError(t, m.Start(context.Background()))
+	t.Cleanup(func() { _ = m.Stop(context.Background()) })
+
+	assert.False(t, debugEnabled())
+	assert.False(t, tracingEnabled(m))
+
+	writeConfig(t, path, "debug on ")
+	assert.Eventually(t, func() bool { return debugEnabled() && tracingEnabled(m) }, time.Second, 10*time.Millisecond)
+
+	// an invalid config keeps the previous one
+	writeConfig(t, path, "")
+	require.Error(t, m.Reload(context.Background()))
+	assert.True(t, debugEnabled())
+	assert.True(t, tracingEnabled(m))
+}
+
+func TestManager_ReloadRejectsConfigFailingValidation(t *testing.T) {
+	prev := logger.L()
+	t.Cleanup(func() { logger.SetLogger(prev) })
+
+	path := filepath.Join(t.TempDir(), "config")
+	writeConfig(t, path, "debug on")
+
+	m := New(fileLoader(path))
+	require.NoError(t, m.Reload(context.Background()))
+	t.Cleanup(func() { _ = m.Stop(context.Background()) })
+	active := m.provider.load()
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "logger", content: "verbose on"},
+		// the grpc exporter requires a collector endpoint
+		{name: "tracing", content: "info on grpc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeConfig(t, path, tt.content)
+			require.Error(t, m.Reload(context.Background()))
+
+			assert.True(t, debugEnabled(), "the previous logger stays active")
+			assert.Same(t, active, m.provider.load(), "the previous tracer provider stays active")
+			assert.True(t, tracingEnabled(m))
+		})
+	}
+}
+
+func TestManager_ReloadAppliesSampleRate(t *testing.T) {
+	prev := logger.L()
+	t.Cleanup(func() { logger.SetLogger(prev) })
+
+	rate := 1.0
+	m := New(func() (Config, error) {
+		return Config{Tracing: &tracingconfig.TracingConfig{
+			ServiceName:      "reload-test",
+			Enabled:          true,
+			ExporterType:     tracingconfig.ExporterTypeStdout,
+			TracesSampleRate: rate,
+		}}, nil
+	})
+	t.Cleanup(func() { _ = m.Stop(context.Background()) })
+
+	sampled := func() int {
+		var n int
+		for i := 0; i < 20; i++ {
+			// spans are not ended, nothing is exported
+			_, span := m.TracerProvider().Tracer("test").Start(context.Background(), "probe")
+			if span.SpanContext().IsSampled() {
+				n++
+			}
+		}
+		return n
+	}
+
+	require.NoError(t, m.Reload(context.Background()))
+	assert.Equal(t, 20, sampled())
+
+	// only the sample rate changes
+	rate = 1e-9
+	require.NoError(t, m.Reload(context.Background()))
+	assert.Equal(t, 0, sampled())
+}
+
+func TestManager_ReloadsOnSignal(t *testing.T) {
+	prev := logger.L()
+	t.Cleanup(func() { logger.SetLogger(prev) })
+
+	path := filepath.Join(t.TempDir(), "config")
+	writeConfig(t, path, "info off")
+
+	m := New(fileLoader(path))
+	require.NoError(t, m.Start(context.Background()))
+	t.Cleanup(func() { _ = m.Stop(context.Background()) })
+
+	writeConfig(t, path, "debug off")
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	assert.Eventually(t, debugEnabled, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,62 @@
+package reload
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// tracerProvider is a trace.TracerProvider whose implementation can be replaced at runtime.
+// Tracers it returns resolve the current provider on every Start, so components holding a
+// tracer (or the provider) pick up a rebuilt provider without being reconfigured.
+type tracerProvider struct {
+	embedded.TracerProvider
+
+	current atomic.Pointer[providerHolder]
+}
+
+// providerHolder wraps the current provider, atomic.Pointer needs a concrete type
+type providerHolder struct {
+	trace.TracerProvider
+}
+
+func newTracerProvider() *tracerProvider {
+	p := &tracerProvider{}
+	p.swap(noop.NewTracerProvider())
+	return p
+}
+
+// swap installs tp and returns the previous provider
+func (p *tracerProvider) swap(tp trace.TracerProvider) trace.TracerProvider {
+	prev := p.current.Swap(&providerHolder{tp})
+	if prev == nil {
+		return nil
+	}
+	return prev.TracerProvider
+}
+
+func (p *tracerProvider) load() trace.TracerProvider {
+	return p.current.Load().TracerProvider
+}
+
+// Tracer implements trace.TracerProvider
+func (p *tracerProvider) Tracer(name string, opts ...trace.TracerOption) trace.Tracer {
+	return &tracer{provider: p, name: name, opts: opts}
+}
+
+// tracer forwards to a tracer of the current provider
+type tracer struct {
+	embedded.Tracer
+
+	provider *tracerProvider
+	name     string
+	opts     []trace.TracerOption
+}
+
+// Start implements trace.Tracer
+func (t *tracer) Start(ctx context.Context, spanName string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return t.provider.load().Tracer(t.name, t.opts...).Start(ctx, spanName, opts...)
+}
@@ -25,6 +25,42 @@ type Config interface {
 	IsLogsEnabled() bool
 }
 
+// SentryConfigProvider is implemented by configs carrying Sentry specific settings, like
+// TracingConfig. It is optional so existing Config implementations keep working.
+type SentryConfigProvider interface {
+	GetSentry() SentryConfig
+}
+
+// SentryOf returns the Sentry settings of cfg, or the zero SentryConfig (the Sentry defaults)
+// when cfg does not implement SentryConfigProvider
+func SentryOf(cfg Config) SentryConfig {
+	if p, ok := cfg.(SentryConfigProvider); ok {
+		return p.GetSentry()
+	}
+	return SentryConfig{}
+}
+
+// SentryConfig holds settings only used by the Sentry exporter
+type SentryConfig struct {
+	EventLevel      string   `koanf:"event_level"`      // Minimum log level sent as Sentry events (default "error")
+	BreadcrumbLevel string   `koanf:"breadcrumb_level"` // Minimum log level recorded as breadcrumbs (default "info")
+	TagKeys         []string `koanf:"tag_keys"`         // Log attributes sent as tags, others are sent in the "log" context
+
+	SampleRate         float64           `koanf:"sample_rate"`         // Error event sample rate 0.0 to 1.0 (0 means 1.0)
+	MaxBreadcrumbs     int               `koanf:"max_breadcrumbs"`     // Breadcrumbs kept per event (0 means the SDK default, negative disables)
+	SendDefaultPII     bool              `koanf:"send_default_pii"`    // Send IPs, cookies and auth headers (auth headers and cookies are still scrubbed)
+	IgnoreErrors       []string          `koanf:"ignore_errors"`       // Regular expressions of error messages that are not sent
+	IgnoreTransactions []string          `koanf:"ignore_transactions"` // Regular expressions of transaction names that are not sent
+	ScrubHeaders       []string          `koanf:"scrub_headers"`       // Extra request headers filtered from events
+	Fingerprints       []FingerprintRule `koanf:"fingerprints"`        // Custom grouping rules, the first match wins
+}
+
+// FingerprintRule groups Sentry events whose exception or message matches a regular expression
+type FingerprintRule struct {
+	Match       string   `koanf:"match"`       // Regular expression matched against "Type: value" of each exception, or the message
+	Fingerprint []string `koanf:"fingerprint"` // Fingerprint of matching events, "{{ default }}" keeps Sentry's grouping as a component
+}
+
 // TracingConfig implements the Config interface
 type TracingConfig struct {
 	ServiceName       string       `koanf:"service_name"`
@@ -37,6 +73,7 @@ type TracingConfig struct {
 	TracesSampleRate  float64      `koanf:"traces_sample_rate"` // 0.0 to 1.0 (0.1 = 10%, 1.0 = 100%)
 	Release           string       `koanf:"release"`            // Release version (e.g., "v1.0.0", git commit hash)
 	EnableLogs        bool         `koanf:"enable_logs"`        // Send logs to Sentry
+	Sentry            SentryConfig `koanf:"sentry"`
 }
 
 // GetServiceName returns the service name
@@ -76,7 +113,7 @@ func (c *TracingConfig) IsDebugMode() bool {
 
 // GetTracesSampleRate returns the traces sample rate (0.0 to 1.0)
 func (c *TracingConfig) GetTracesSampleRate() float64 {
-	if c.TracesSampleRate <= 0 {
+	if c.TracesSampleRate == 0 {
 		return 1.0 // Default to 100% if not set
 	}
 	return c.TracesSampleRate
@@ -91,3 +128,8 @@ func (c *TracingConfig) GetRelease() string {
 func (c *TracingConfig) IsLogsEnabled() bool {
 	return c.EnableLogs
 }
+
+// GetSentry returns the Sentry specific settings
+func (c *TracingConfig) GetSentry() SentryConfig {
+	return c.Sentry
+}
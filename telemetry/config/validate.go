@@ -0,0 +1,79 @@
+package config
+
+import (
+	"errors"
+	"log/slog"
+	"net/url"
+	"regexp"
+
+	obserrors "github.com/ubin/go-observability/errors"
+)
+
+// Validate returns every invalid field of the configuration joined in one error
+func (c *TracingConfig) Validate() error {
+	return Validate(c)
+}
+
+// Validate checks a tracing configuration and returns every invalid field joined in one error,
+// each one being an *errors.FieldError
+func Validate(cfg Config) error {
+	var errs []error
+
+	switch cfg.GetExporterType() {
+	case ExporterTypeHTTP, ExporterTypeGRPC:
+		if cfg.GetCollectorEndpoint() == "" {
+			errs = append(errs, &obserrors.FieldError{Field: "collector_endpoint", Value: "", Reason: "is required for the " + string(cfg.GetExporterType()) + " exporter"})
+		}
+	case ExporterTypeSentry:
+		// an empty DSN is accepted by Sentry and disables sending
+		if dsn := cfg.GetCollectorEndpoint(); dsn != "" {
+			if u, err := url.Parse(dsn); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				errs = append(errs, &obserrors.FieldError{Field: "collector_endpoint", Value: dsn, Reason: "must be a Sentry DSN like https://key@host/project"})
+			}
+		}
+		errs = append(errs, validateSentry(SentryOf(cfg))...)
+	case ExporterTypeStdout:
+	default:
+		errs = append(errs, &obserrors.FieldError{Field: "exporter_type", Value: cfg.GetExporterType(), Reason: "must be one of http, grpc, stdout, sentry"})
+	}
+
+	if rate := cfg.GetTracesSampleRate(); rate < 0 || rate > 1 {
+		errs = append(errs, &obserrors.FieldError{Field: "traces_sample_rate", Value: rate, Reason: "must be between 0.0 and 1.0"})
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateSentry(cfg SentryConfig) []error {
+	var errs []error
+
+	levels := []struct{ field, value string }{
+		{"sentry.event_level", cfg.EventLevel},
+		{"sentry.breadcrumb_level", cfg.BreadcrumbLevel},
+	}
+	for _, l := range levels {
+		var level slog.Level
+		if l.value != "" && level.UnmarshalText([]byte(l.value)) != nil {
+			errs = append(errs, &obserrors.FieldError{Field: l.field, Value: l.value, Reason: "must be one of debug, info, warn, error"})
+		}
+	}
+	if cfg.SampleRate < 0 || cfg.SampleRate > 1 {
+		errs = append(errs, &obserrors.FieldError{Field: "sentry.sample_rate", Value: cfg.SampleRate, Reason: "must be between 0.0 and 1.0"})
+	}
+	for _, expr := range cfg.IgnoreErrors {
+		if _, err := regexp.Compile(expr); err != nil {
+			errs = append(errs, &obserrors.FieldError{Field: "sentry.ignore_errors", Value: expr, Reason: "must be a valid regular expression"})
+		}
+	}
+	for _, expr := range cfg.IgnoreTransactions {
+		if _, err := regexp.Compile(expr); err != nil {
+			errs = append(errs, &obserrors.FieldError{Field: "sentry.ignore_transactions", Value: expr, Reason: "must be a valid regular expression"})
+		}
+	}
+	for _, rule := range cfg.Fingerprints {
+		if _, err := regexp.Compile(rule.Match); err != nil {
+			errs = append(errs, &obserrors.FieldError{Field: "sentry.fingerprints.match", Value: rule.Match, Reason: "must be a valid regular expression"})
+		}
+	}
+	return errs
+}
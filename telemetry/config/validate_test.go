@@ -0,0 +1,55 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	obserrors "github.com/ubin/go-observability/errors"
+)
+
+func fields(err error) []string {
+	var names []string
+	for _, fe := range obserrors.FieldErrors(err) {
+		names = append(names, fe.Field)
+	}
+	return names
+}
+
+func TestTracingConfig_Validate(t *testing.T) {
+	valid := &TracingConfig{ExporterType: ExporterTypeGRPC, CollectorEndpoint: "localhost:4317", TracesSampleRate: 0.5}
+	assert.NoError(t, valid.Validate())
+
+	stdout := &TracingConfig{ExporterType: ExporterTypeStdout}
+	assert.NoError(t, stdout.Validate(), "zero sample rate means the default")
+
+	invalid := &TracingConfig{ExporterType: ExporterTypeGRPC, TracesSampleRate: 1.5}
+	assert.ElementsMatch(t, []string{"collector_endpoint", "traces_sample_rate"}, fields(invalid.Validate()))
+
+	unknown := &TracingConfig{ExporterType: "zipkin"}
+	assert.Equal(t, []string{"exporter_type"}, fields(unknown.Validate()))
+
+	sentry := &TracingConfig{
+		ExporterType:      ExporterTypeSentry,
+		CollectorEndpoint: "not a dsn",
+		Sentry: SentryConfig{
+			EventLevel:   "loud",
+			Fingerprints: []FingerprintRule{{Match: "("}},
+		},
+	}
+	assert.Equal(t, []string{"collector_endpoint", "sentry.event_level", "sentry.fingerprints.match"}, fields(sentry.Validate()))
+}
+
+// legacyConfig is a Config implemented before SentryConfigProvider existed
+type legacyConfig struct{ TracingConfig }
+
+// GetSentry hides the promoted TracingConfig.GetSentry
+func (c *legacyConfig) GetSentry() {}
+
+func TestSentryOf(t *testing.T) {
+	cfg := &TracingConfig{ExporterType: ExporterTypeSentry, Sentry: SentryConfig{EventLevel: "warn"}}
+	assert.Equal(t, "warn", SentryOf(cfg).EventLevel)
+
+	legacy := &legacyConfig{TracingConfig: *cfg}
+	assert.Equal(t, SentryConfig{}, SentryOf(legacy), "configs without GetSentry use the defaults")
+	assert.NoError(t, Validate(legacy))
+}
@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 
-	"github.com/ubin/go-observability/telemetry/config"
+	obserrors "github.com/ubin/go-observability/errors"
 	"github.com/ubin/go-observability/telemetry/provider/sentry"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
@@ -13,14 +13,11 @@ import (
 
 type OtelHandler struct {
 	wrapped slog.Handler
-	// otelCfg config.Tracing
-	otelProvider string
 }
 
 func NewOtelHandler(wrapped slog.Handler) *OtelHandler {
 	return &OtelHandler{
-		wrapped:      wrapped,
-		otelProvider: "sentry",
+		wrapped: wrapped,
 	}
 }
 
@@ -38,10 +35,9 @@ func (h *OtelHandler) Handle(ctx context.Context, r slog.Record) error {
 		)
 	}
 
-	// if h.otelCfg.ExporterType == config.ExporterTypeSentry {
-	if h.otelProvider == string(config.ExporterTypeSentry) {
-		// Send logs as messages to Sentry
-		sentry.CaptureLogMessage(r)
+	// Report to Sentry only when the sentry exporter initialized a client
+	if sentry.Enabled() {
+		sentry.CaptureLogMessage(ctx, r)
 	}
 
 	// Delegate actual logging to the wrapped handler
@@ -70,7 +66,8 @@ func AddLogToSpan(ctx context.Context, level slog.Level, msg string, keyvals ...
 		attrs := make([]attribute.KeyValue, 0, len(keyvals)/2+2)
 		attrs = append(attrs, attribute.String("log.level", level.String()))
 		attrs = append(attrs, attribute.String("log.message", msg))
-		for i := 0; i < len(keyvals); i += 2 {
+		var capturedError error
+		for i := 0; i+1 < len(keyvals); i += 2 {
 			key, ok := keyvals[i].(string)
 			if !ok {
 				continue
@@ -86,10 +83,22 @@ func AddLogToSpan(ctx context.Context, level slog.Level, msg string, keyvals ...
 				attrs = append(attrs, attribute.Bool(key, v))
 			case float64:
 				attrs = append(attrs, attribute.Float64(key, v))
+			case error:
+				capturedError = v
+				attrs = append(attrs, attribute.String(key, v.Error()))
 			default:
 				attrs = append(attrs, attribute.String(key, fmt.Sprintf("%v", v)))
 			}
 		}
+
+		// errors carrying a stack are recorded as exceptions so the trace shows where they happened
+		if level >= slog.LevelError && capturedError != nil {
+			if stack := obserrors.Stack(capturedError); stack != "" {
+				attrs = append(attrs, attribute.String("exception.stacktrace", stack))
+			}
+			span.RecordError(capturedError, trace.WithAttributes(attrs...))
+			return
+		}
 		span.AddEvent("log", trace.WithAttributes(attrs...))
 	}
 }
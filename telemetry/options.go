@@ -0,0 +1,27 @@
+package telemetry
+
+import (
+	"github.com/ubin/go-observability/telemetry/provider/sentry"
+)
+
+// Option customizes InitTracer beyond what config.Config can express
+type Option func(*options)
+
+type options struct {
+	sentryOptions []sentry.Option
+	setGlobals    bool
+}
+
+// WithGlobals installs the tracer provider, propagator and error handler as OpenTelemetry globals
+func WithGlobals() Option {
+	return func(o *options) {
+		o.setGlobals = true
+	}
+}
+
+// WithSentryOptions passes options such as before-send hooks to the Sentry exporter
+func WithSentryOptions(opts ...sentry.Option) Option {
+	return func(o *options) {
+		o.sentryOptions = append(o.sentryOptions, opts...)
+	}
+}
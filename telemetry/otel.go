@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/ubin/go-observability/logger"
 	"github.com/ubin/go-observability/telemetry/config"
 	"github.com/ubin/go-observability/telemetry/provider/sentry"
 
@@ -19,25 +20,57 @@ import (
 	"go.opentelemetry.io/otel/sdk/trace"
 )
 
-// InitTracer initializes OpenTelemetry tracing with configurable exporters.
-func InitTracer(cfg config.Config) (*trace.TracerProvider, error) {
-	ctx := context.Background()
+// InitTracer initializes OpenTelemetry tracing with configurable exporters and installs the
+// tracer provider, propagator and error handler as OpenTelemetry globals.
+func InitTracer(cfg config.Config, opts ...Option) (*trace.TracerProvider, error) {
+	return NewTracerProvider(cfg, append(opts, WithGlobals())...)
+}
+
+// NewTracerProvider creates a tracer provider for the configured exporter. OpenTelemetry globals
+// are left untouched unless WithGlobals is passed, so several providers can coexist (e.g. in tests).
+// The sentry exporter is the exception: the Sentry SDK client is process wide, building a provider
+// with it calls sentry.Init and replaces the client of the current Sentry hub.
+func NewTracerProvider(cfg config.Config, opts ...Option) (*trace.TracerProvider, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if err := config.Validate(cfg); err != nil {
+		return nil, fmt.Errorf("invalid tracing config: %w", err)
+	}
+
+	tracerProvider, err := newTracerProvider(context.Background(), cfg, o)
+	if err != nil {
+		return nil, err
+	}
 
+	if o.setGlobals {
+		otel.SetTextMapPropagator(Propagator())
+		// route exporter failures and dropped spans into the application logs
+		otel.SetErrorHandler(logger.NewOtelErrorHandler(logger.DefaultOtelErrorInterval))
+		otel.SetTracerProvider(tracerProvider)
+	}
+	return tracerProvider, nil
+}
+
+// Propagator returns the propagator used by this module: W3C trace context and baggage
+func Propagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+}
+
+func newTracerProvider(ctx context.Context, cfg config.Config, o options) (*trace.TracerProvider, error) {
 	var exporter trace.SpanExporter
 	var err error
 
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
-
 	switch cfg.GetExporterType() {
 	case config.ExporterTypeSentry:
 		//initialize sentry sdk
-		s, err := sentry.New(cfg)
+		s, err := sentry.New(cfg, o.sentryOptions...)
 		if err != nil {
 			return nil, fmt.Errorf("sentry initialization failed: %w", err)
 		}
-		tracerProvider := s.TracerProvider()
-		otel.SetTracerProvider(tracerProvider)
-		return tracerProvider, nil
+		return s.TracerProvider(), nil
 
 	case "signoz":
 		// endpoint := os.Getenv("OTEL_SIGNOZ_ENDPOINT")
@@ -92,8 +125,8 @@ func InitTracer(cfg config.Config) (*trace.TracerProvider, error) {
 	tracerProvider := trace.NewTracerProvider(
 		trace.WithBatcher(exporter),
 		trace.WithResource(resource),
+		trace.WithSampler(trace.ParentBased(trace.TraceIDRatioBased(cfg.GetTracesSampleRate()))),
 	)
-	otel.SetTracerProvider(tracerProvider)
 
 	return tracerProvider, nil
 }
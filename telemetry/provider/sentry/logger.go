@@ -1,23 +1,215 @@
 package sentry
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"weak"
 
 	"github.com/getsentry/sentry-go"
+	obserrors "github.com/ubin/go-observability/errors"
+	logconfig "github.com/ubin/go-observability/logger/loggerfactory/config"
 )
 
-// sends slog messages to sentry as events
-func CaptureLogMessage(r slog.Record) {
+// LogOptions controls how slog records are reported to Sentry
+type LogOptions struct {
+	// EventLevel is the minimum level sent as a Sentry event (default slog.LevelError)
+	EventLevel slog.Level
+	// BreadcrumbLevel is the minimum level recorded as a breadcrumb, records between
+	// BreadcrumbLevel and EventLevel are attached to the next event (default slog.LevelInfo)
+	BreadcrumbLevel slog.Level
+	// TagKeys are attribute keys recorded as searchable tags, other attributes go to the "log" context
+	TagKeys []string
+}
+
+// defaultTagKeys are always recorded as tags
+var defaultTagKeys = []string{"request_id", "trace_id", "span_id"}
+
+// DefaultLogOptions returns the options used until SetLogOptions is called
+func DefaultLogOptions() LogOptions {
+	return LogOptions{
+		EventLevel:      slog.LevelError,
+		BreadcrumbLevel: slog.LevelInfo,
+	}
+}
+
+type logOptions struct {
+	LogOptions
+	tagKeys map[string]struct{}
+}
+
+func newLogOptions(opts LogOptions) *logOptions {
+	o := &logOptions{
+		LogOptions: opts,
+		tagKeys:    make(map[string]struct{}, len(defaultTagKeys)+len(opts.TagKeys)),
+	}
+	for _, k := range defaultTagKeys {
+		o.tagKeys[k] = struct{}{}
+	}
+	for _, k := range opts.TagKeys {
+		o.tagKeys[k] = struct{}{}
+	}
+	return o
+}
+
+var currentLogOptions atomic.Pointer[logOptions]
+
+// clientLogOptions holds the options of the clients created by New, keyed by a weak pointer
+// to the client so entries go away with it
+var clientLogOptions sync.Map
+
+func init() {
+	SetLogOptions(DefaultLogOptions())
+}
+
+// SetLogOptions replaces the options used by CaptureLogMessage for Sentry clients not created
+// by New, e.g. initialized by the application with sentry.Init
+func SetLogOptions(opts LogOptions) {
+	currentLogOptions.Store(newLogOptions(opts))
+}
+
+// setClientLogOptions attaches the options used by CaptureLogMessage to records reported
+// through client
+func setClientLogOptions(client *sentry.Client, opts LogOptions) {
+	key := weak.Make(client)
+	clientLogOptions.Store(key, newLogOptions(opts))
+	runtime.AddCleanup(client, func(key weak.Pointer[sentry.Client]) { clientLogOptions.Delete(key) }, key)
+}
+
+// logOptionsFor returns the options attached to client, the ones set by SetLogOptions otherwise
+func logOptionsFor(client *sentry.Client) *logOptions {
+	if o, ok := clientLogOptions.Load(weak.Make(client)); ok {
+		return o.(*logOptions)
+	}
+	return currentLogOptions.Load()
+}
 
-	// Send logs as messages to Sentry
-	sentry.WithScope(func(scope *sentry.Scope) {
-		scope.SetExtra("level", r.Level.String())
-		scope.SetExtra("message", r.Message)
+// Enabled reports whether a Sentry client has been initialized
+func Enabled() bool {
+	return sentry.CurrentHub().Client() != nil
+}
+
+// reportedKey is the context key set by WithReported
+type reportedKey struct{}
+
+// WithReported returns a context marking the error being logged as already reported to Sentry,
+// e.g. a recovered panic captured with hub.Recover. CaptureLogMessage does not send records
+// logged with it as events again.
+func WithReported(ctx context.Context) context.Context {
+	return context.WithValue(ctx, reportedKey{}, true)
+}
+
+// reported reports whether ctx was marked by WithReported
+func reported(ctx context.Context) bool {
+	v, _ := ctx.Value(reportedKey{}).(bool)
+	return v
+}
+
+// CaptureLogMessage reports a slog record to Sentry using the hub bound to ctx (e.g. by the
+// HTTP middleware) or the global hub. Records at or above the event level are sent as events
+// (as exceptions when an error attribute is present), records at or above the breadcrumb
+// level are kept as breadcrumbs, anything lower is ignored. Records logged with a context
+// marked by WithReported are not sent.
+func CaptureLogMessage(ctx context.Context, r slog.Record) {
+	hub := sentry.GetHubFromContext(ctx)
+	if hub == nil {
+		hub = sentry.CurrentHub()
+	}
+	client := hub.Client()
+	if client == nil {
+		return
+	}
+
+	opts := logOptionsFor(client)
+	if r.Level < opts.BreadcrumbLevel && r.Level < opts.EventLevel {
+		return
+	}
+	if r.Level >= opts.EventLevel && reported(ctx) {
+		return
+	}
+
+	if r.Level < opts.EventLevel {
+		data := make(map[string]interface{}, r.NumAttrs())
 		r.Attrs(func(a slog.Attr) bool {
-			scope.SetExtra(a.Key, a.Value.Any())
+			data[a.Key] = attrValue(a.Value)
 			return true
 		})
-		sentry.CaptureMessage(r.Message)
+		hub.AddBreadcrumb(&sentry.Breadcrumb{
+			Type:      "default",
+			Category:  "log",
+			Message:   r.Message,
+			Level:     toSentryLevel(r.Level),
+			Data:      data,
+			Timestamp: r.Time,
+		}, nil)
+		return
+	}
+
+	hub.WithScope(func(scope *sentry.Scope) {
+		var capturedError error
+		logContext := sentry.Context{"message": r.Message}
+
+		scope.SetLevel(toSentryLevel(r.Level))
+		r.Attrs(func(a slog.Attr) bool {
+			if err, ok := a.Value.Any().(error); ok {
+				capturedError = err
+			}
+			if _, ok := opts.tagKeys[a.Key]; ok {
+				scope.SetTag(a.Key, a.Value.String())
+				return true
+			}
+			logContext[a.Key] = attrValue(a.Value)
+			return true
+		})
+		scope.SetContext("log", logContext)
+
+		if capturedError != nil {
+			scope.SetTag("error.kind", obserrors.KindOf(capturedError).String())
+			hub.CaptureException(capturedError)
+			return
+		}
+		hub.CaptureMessage(r.Message)
 	})
+}
+
+// attrValue converts a slog value into something the Sentry JSON encoder handles
+func attrValue(v slog.Value) interface{} {
+	v = v.Resolve()
+	switch v.Kind() {
+	case slog.KindGroup:
+		group := make(map[string]interface{}, len(v.Group()))
+		for _, a := range v.Group() {
+			group[a.Key] = attrValue(a.Value)
+		}
+		return group
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return err.Error()
+		}
+		if s, ok := v.Any().(fmt.Stringer); ok {
+			return s.String()
+		}
+		return v.Any()
+	default:
+		return v.Any()
+	}
+}
 
+// toSentryLevel maps a slog level to the closest Sentry level
+func toSentryLevel(level slog.Level) sentry.Level {
+	switch {
+	case level < slog.LevelInfo:
+		return sentry.LevelDebug
+	case level < slog.LevelWarn:
+		return sentry.LevelInfo
+	case level < slog.LevelError:
+		return sentry.LevelWarning
+	case level < logconfig.LevelPanic:
+		return sentry.LevelError
+	default:
+		return sentry.LevelFatal
+	}
 }
@@ -0,0 +1,170 @@
+package sentry
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/ubin/go-observability/telemetry/config"
+)
+
+// filteredValue replaces scrubbed data in events
+const filteredValue = "[Filtered]"
+
+// defaultScrubbedHeaders are always removed from events, whatever SendDefaultPII says
+var defaultScrubbedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+}
+
+// EventProcessor is the signature of the Sentry before-send callbacks
+type EventProcessor func(event *sentry.Event, hint *sentry.EventHint) *sentry.Event
+
+// Option customizes the Sentry client beyond what the configuration can express
+type Option func(*options)
+
+type options struct {
+	beforeSend            EventProcessor
+	beforeSendTransaction EventProcessor
+	fingerprint           func(event *sentry.Event, hint *sentry.EventHint) []string
+	transport             sentry.Transport
+}
+
+// WithBeforeSend sets a callback run on error events after the built-in scrubbing and
+// fingerprinting, returning nil drops the event
+func WithBeforeSend(fn EventProcessor) Option {
+	return func(o *options) {
+		o.beforeSend = fn
+	}
+}
+
+// WithBeforeSendTransaction sets a callback run on transactions after the built-in scrubbing,
+// returning nil drops the transaction
+func WithBeforeSendTransaction(fn EventProcessor) Option {
+	return func(o *options) {
+		o.beforeSendTransaction = fn
+	}
+}
+
+// WithFingerprint sets a callback returning the fingerprint of an error event.
+// It runs after the configured fingerprint rules, an empty result keeps the current fingerprint.
+func WithFingerprint(fn func(event *sentry.Event, hint *sentry.EventHint) []string) Option {
+	return func(o *options) {
+		o.fingerprint = fn
+	}
+}
+
+// WithTransport replaces the HTTP transport used to send events, e.g. by a fake in tests
+func WithTransport(transport sentry.Transport) Option {
+	return func(o *options) {
+		o.transport = transport
+	}
+}
+
+// fingerprintRule is a compiled config.FingerprintRule
+type fingerprintRule struct {
+	match       *regexp.Regexp
+	fingerprint []string
+}
+
+// applyClientOptions copies the Sentry specific config and options onto the client options
+func applyClientOptions(clientOptions *sentry.ClientOptions, cfg config.SentryConfig, opts []Option) error {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	rules := make([]fingerprintRule, 0, len(cfg.Fingerprints))
+	for _, r := range cfg.Fingerprints {
+		match, err := regexp.Compile(r.Match)
+		if err != nil {
+			return fmt.Errorf("invalid sentry fingerprint rule %q: %w", r.Match, err)
+		}
+		rules = append(rules, fingerprintRule{match: match, fingerprint: r.Fingerprint})
+	}
+
+	scrubbed := make(map[string]struct{}, len(defaultScrubbedHeaders)+len(cfg.ScrubHeaders))
+	for _, h := range append(defaultScrubbedHeaders, cfg.ScrubHeaders...) {
+		scrubbed[http.CanonicalHeaderKey(h)] = struct{}{}
+	}
+
+	clientOptions.SampleRate = cfg.SampleRate
+	clientOptions.MaxBreadcrumbs = cfg.MaxBreadcrumbs
+	clientOptions.SendDefaultPII = cfg.SendDefaultPII
+	clientOptions.IgnoreErrors = cfg.IgnoreErrors
+	clientOptions.IgnoreTransactions = cfg.IgnoreTransactions
+	if o.transport != nil {
+		clientOptions.Transport = o.transport
+	}
+
+	clientOptions.BeforeSend = func(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+		scrubEvent(event, scrubbed)
+		applyFingerprint(event, hint, rules, o.fingerprint)
+		if o.beforeSend != nil {
+			return o.beforeSend(event, hint)
+		}
+		return event
+	}
+	clientOptions.BeforeSendTransaction = func(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+		scrubEvent(event, scrubbed)
+		if o.beforeSendTransaction != nil {
+			return o.beforeSendTransaction(event, hint)
+		}
+		return event
+	}
+	return nil
+}
+
+// scrubEvent removes credentials from the request attached to the event
+func scrubEvent(event *sentry.Event, scrubbed map[string]struct{}) {
+	if event == nil || event.Request == nil {
+		return
+	}
+	for k := range event.Request.Headers {
+		if _, ok := scrubbed[http.CanonicalHeaderKey(k)]; ok {
+			event.Request.Headers[k] = filteredValue
+		}
+	}
+	if event.Request.Cookies != "" {
+		event.Request.Cookies = filteredValue
+	}
+}
+
+// applyFingerprint sets the fingerprint from the first matching rule, then from the callback
+func applyFingerprint(event *sentry.Event, hint *sentry.EventHint, rules []fingerprintRule, fn func(*sentry.Event, *sentry.EventHint) []string) {
+	if event == nil {
+		return
+	}
+	if len(rules) > 0 {
+		subject := fingerprintSubject(event)
+		for _, r := range rules {
+			if r.match.MatchString(subject) {
+				event.Fingerprint = r.fingerprint
+				break
+			}
+		}
+	}
+	if fn != nil {
+		if fingerprint := fn(event, hint); len(fingerprint) > 0 {
+			event.Fingerprint = fingerprint
+		}
+	}
+}
+
+// fingerprintSubject is the text rules are matched against: exception types and values, or the message
+func fingerprintSubject(event *sentry.Event) string {
+	if len(event.Exception) == 0 {
+		return event.Message
+	}
+	parts := make([]string, 0, len(event.Exception))
+	for _, e := range event.Exception {
+		parts = append(parts, e.Type+": "+e.Value)
+	}
+	return strings.Join(parts, "\n")
+}
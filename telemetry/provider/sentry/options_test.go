@@ -0,0 +1,54 @@
+package sentry
+
+import (
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ubin/go-observability/telemetry/config"
+)
+
+func TestApplyClientOptions_ScrubsAndFingerprints(t *testing.T) {
+	var clientOptions sentry.ClientOptions
+	err := applyClientOptions(&clientOptions, config.SentryConfig{
+		ScrubHeaders: []string{"x-partner-secret"},
+		Fingerprints: []config.FingerprintRule{
+			{Match: "connection refused", Fingerprint: []string{"database-unavailable"}},
+		},
+	}, []Option{
+		WithBeforeSend(func(event *sentry.Event, _ *sentry.EventHint) *sentry.Event {
+			event.Tags = map[string]string{"hooked": "true"}
+			return event
+		}),
+	})
+	require.NoError(t, err)
+
+	event := &sentry.Event{
+		Message: "dial tcp: connection refused",
+		Request: &sentry.Request{
+			Cookies: "session=abc",
+			Headers: map[string]string{
+				"Authorization":    "Bearer token",
+				"X-Partner-Secret": "s3cr3t",
+				"Accept":           "application/json",
+			},
+		},
+	}
+	event = clientOptions.BeforeSend(event, &sentry.EventHint{})
+
+	assert.Equal(t, filteredValue, event.Request.Headers["Authorization"])
+	assert.Equal(t, filteredValue, event.Request.Headers["X-Partner-Secret"])
+	assert.Equal(t, "application/json", event.Request.Headers["Accept"])
+	assert.Equal(t, filteredValue, event.Request.Cookies)
+	assert.Equal(t, []string{"database-unavailable"}, event.Fingerprint)
+	assert.Equal(t, "true", event.Tags["hooked"])
+}
+
+func TestApplyClientOptions_InvalidFingerprintRule(t *testing.T) {
+	var clientOptions sentry.ClientOptions
+	err := applyClientOptions(&clientOptions, config.SentryConfig{
+		Fingerprints: []config.FingerprintRule{{Match: "("}},
+	}, nil)
+	assert.Error(t, err)
+}
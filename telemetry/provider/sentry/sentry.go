@@ -1,18 +1,27 @@
 package sentry
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/getsentry/sentry-go"
 	sentryotel "github.com/getsentry/sentry-go/otel"
 	"github.com/ubin/go-observability/telemetry/config"
 	"go.opentelemetry.io/otel/sdk/trace"
 )
 
+// DefaultFlushTimeout is used to flush events when the context has no deadline
+const DefaultFlushTimeout = 2 * time.Second
+
 type Sentry struct {
 	cfg config.Config
 }
 
-// New initializes Sentry for error monitoring and distributed tracing
-func New(cfg config.Config) (*Sentry, error) {
+// New initializes Sentry for error monitoring and distributed tracing. The Sentry SDK client is
+// process wide: New binds the client it creates to the current hub with sentry.Init, replacing
+// the previous one. The log reporting options of the configuration are attached to that client.
+func New(cfg config.Config, opts ...Option) (*Sentry, error) {
 	s := Sentry{
 		cfg: cfg,
 	}
@@ -33,17 +42,81 @@ func New(cfg config.Config) (*Sentry, error) {
 		clientOptions.ServerName = cfg.GetServiceName()
 	}
 
-	err := sentry.Init(clientOptions)
+	sentryConfig := config.SentryOf(cfg)
+	if err := applyClientOptions(&clientOptions, sentryConfig, opts); err != nil {
+		return nil, err
+	}
+
+	logOptions, err := logOptionsFromConfig(sentryConfig)
 	if err != nil {
 		return nil, err
 	}
+
+	err = sentry.Init(clientOptions)
+	if err != nil {
+		return nil, err
+	}
+	setClientLogOptions(sentry.CurrentHub().Client(), logOptions)
 	return &s, nil
 }
 
+// logOptionsFromConfig converts the configured level names into LogOptions
+func logOptionsFromConfig(cfg config.SentryConfig) (LogOptions, error) {
+	opts := DefaultLogOptions()
+	opts.TagKeys = cfg.TagKeys
+	if cfg.EventLevel != "" {
+		if err := opts.EventLevel.UnmarshalText([]byte(cfg.EventLevel)); err != nil {
+			return opts, fmt.Errorf("invalid sentry event level: %w", err)
+		}
+	}
+	if cfg.BreadcrumbLevel != "" {
+		if err := opts.BreadcrumbLevel.UnmarshalText([]byte(cfg.BreadcrumbLevel)); err != nil {
+			return opts, fmt.Errorf("invalid sentry breadcrumb level: %w", err)
+		}
+	}
+	return opts, nil
+}
+
+// TracerProvider returns a tracer provider sending spans to Sentry.
+// Its ForceFlush and Shutdown flush buffered Sentry events within the context deadline.
 func (s *Sentry) TracerProvider() *trace.TracerProvider {
 	tracerProvider := trace.NewTracerProvider(
 		trace.WithSpanProcessor(sentryotel.NewSentrySpanProcessor()),
+		// registered last so it flushes after the Sentry span processor finished its transactions
+		trace.WithSpanProcessor(flushProcessor{}),
 	)
 
 	return tracerProvider
 }
+
+// Flush waits until buffered events are sent or the context deadline
+// (DefaultFlushTimeout without deadline) is reached
+func (s *Sentry) Flush(ctx context.Context) error {
+	return flush(ctx)
+}
+
+func flush(ctx context.Context) error {
+	timeout := DefaultFlushTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	if !sentry.Flush(timeout) {
+		return fmt.Errorf("sentry flush did not complete within %s", timeout)
+	}
+	return nil
+}
+
+// flushProcessor ties the Sentry client buffer to the tracer provider lifecycle
+type flushProcessor struct{}
+
+func (flushProcessor) OnStart(context.Context, trace.ReadWriteSpan) {}
+
+func (flushProcessor) OnEnd(trace.ReadOnlySpan) {}
+
+func (flushProcessor) ForceFlush(ctx context.Context) error {
+	return flush(ctx)
+}
+
+func (flushProcessor) Shutdown(ctx context.Context) error {
+	return flush(ctx)
+}
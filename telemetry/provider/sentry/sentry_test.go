@@ -0,0 +1,170 @@
+package sentry
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	obserrors "github.com/ubin/go-observability/errors"
+	"github.com/ubin/go-observability/telemetry/config"
+)
+
+// fakeTransport buffers events until flushed, recording what would have been sent to Sentry
+type fakeTransport struct {
+	mu       sync.Mutex
+	pending  []*sentry.Event
+	sent     []*sentry.Event
+	flushes  int
+	timeouts []time.Duration
+}
+
+func (t *fakeTransport) Configure(sentry.ClientOptions) {}
+
+func (t *fakeTransport) SendEvent(event *sentry.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pending = append(t.pending, event)
+}
+
+func (t *fakeTransport) Flush(timeout time.Duration) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.flushes++
+	t.timeouts = append(t.timeouts, timeout)
+	t.sent = append(t.sent, t.pending...)
+	t.pending = nil
+	return true
+}
+
+func (t *fakeTransport) FlushWithContext(ctx context.Context) bool {
+	timeout := DefaultFlushTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	return t.Flush(timeout)
+}
+
+func (t *fakeTransport) Close() {}
+
+func (t *fakeTransport) events() []*sentry.Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]*sentry.Event(nil), t.sent...)
+}
+
+func newTestSentry(t *testing.T, transport *fakeTransport) *Sentry {
+	t.Helper()
+	s, err := New(&config.TracingConfig{
+		ServiceName:       "test-service",
+		Environment:       "test",
+		ExporterType:      config.ExporterTypeSentry,
+		CollectorEndpoint: "https://public@example.com/1",
+	}, WithTransport(transport))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		sentry.CurrentHub().BindClient(nil)
+		SetLogOptions(DefaultLogOptions())
+	})
+	return s
+}
+
+func TestTracerProviderShutdown_FlushesWithDeadline(t *testing.T) {
+	transport := &fakeTransport{}
+	s := newTestSentry(t, transport)
+	tp := s.TracerProvider()
+
+	sentry.CaptureMessage("buffered before shutdown")
+	assert.Empty(t, transport.events(), "events stay buffered until flushed")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, tp.Shutdown(ctx))
+
+	events := transport.events()
+	require.Len(t, events, 1)
+	assert.Equal(t, "buffered before shutdown", events[0].Message)
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	require.NotEmpty(t, transport.timeouts)
+	last := transport.timeouts[len(transport.timeouts)-1]
+	assert.LessOrEqual(t, last, 5*time.Second)
+	assert.Greater(t, last, 4*time.Second, "flush should use the context deadline, not the default timeout")
+}
+
+func TestCaptureLogMessage_LevelsAndExceptions(t *testing.T) {
+	transport := &fakeTransport{}
+	s := newTestSentry(t, transport)
+
+	info := slog.NewRecord(time.Now(), slog.LevelInfo, "cache miss", 0)
+	info.AddAttrs(slog.String("key", "user:1"))
+	CaptureLogMessage(context.Background(), info)
+
+	failure := slog.NewRecord(time.Now(), slog.LevelError, "load user failed", 0)
+	failure.AddAttrs(
+		slog.Any("error", obserrors.New("db down").WithKind(obserrors.KindDependency)),
+		slog.String("request_id", "req-1"),
+	)
+	CaptureLogMessage(context.Background(), failure)
+
+	require.NoError(t, s.Flush(context.Background()))
+
+	events := transport.events()
+	require.Len(t, events, 1, "info records are breadcrumbs, not events")
+	event := events[0]
+	assert.Equal(t, sentry.LevelError, event.Level)
+	require.NotEmpty(t, event.Exception)
+	assert.Equal(t, "db down", event.Exception[len(event.Exception)-1].Value)
+	assert.NotNil(t, event.Exception[len(event.Exception)-1].Stacktrace, "stack captured by the errors package")
+	assert.Equal(t, "req-1", event.Tags["request_id"])
+	assert.Equal(t, "dependency", event.Tags["error.kind"])
+	require.Len(t, event.Breadcrumbs, 1)
+	assert.Equal(t, "cache miss", event.Breadcrumbs[0].Message)
+}
+
+func TestCaptureLogMessage_SkipsReportedErrors(t *testing.T) {
+	transport := &fakeTransport{}
+	s := newTestSentry(t, transport)
+
+	record := slog.NewRecord(time.Now(), slog.LevelError, "handler panic", 0)
+	CaptureLogMessage(WithReported(context.Background()), record)
+	CaptureLogMessage(context.Background(), record)
+	require.NoError(t, s.Flush(context.Background()))
+
+	assert.Len(t, transport.events(), 1, "only the unmarked record is sent")
+}
+
+func TestNew_AttachesLogOptionsToItsClient(t *testing.T) {
+	transport := &fakeTransport{}
+	s, err := New(&config.TracingConfig{
+		ServiceName:       "test-service",
+		ExporterType:      config.ExporterTypeSentry,
+		CollectorEndpoint: "https://public@example.com/1",
+		Sentry:            config.SentryConfig{EventLevel: "warn"},
+	}, WithTransport(transport))
+	require.NoError(t, err)
+	t.Cleanup(func() { sentry.CurrentHub().BindClient(nil) })
+
+	assert.Equal(t, DefaultLogOptions().EventLevel, currentLogOptions.Load().EventLevel,
+		"the options set by SetLogOptions are left alone")
+
+	// a client the application created keeps the default options
+	otherTransport := &fakeTransport{}
+	other, err := sentry.NewClient(sentry.ClientOptions{Dsn: "https://public@example.com/2", Transport: otherTransport})
+	require.NoError(t, err)
+	otherHub := sentry.NewHub(other, sentry.NewScope())
+
+	warn := slog.NewRecord(time.Now(), slog.LevelWarn, "slow query", 0)
+	CaptureLogMessage(context.Background(), warn)
+	CaptureLogMessage(sentry.SetHubOnContext(context.Background(), otherHub), warn)
+	require.NoError(t, s.Flush(context.Background()))
+	other.Flush(time.Second)
+
+	assert.Len(t, transport.events(), 1, "warnings are events for the configured client")
+	assert.Empty(t, otherTransport.events(), "and breadcrumbs for the others")
+}
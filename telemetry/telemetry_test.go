@@ -5,9 +5,11 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/ubin/go-observability/telemetry/config"
+	"go.opentelemetry.io/otel"
 )
 
-// MockConfig is a mock implementation of the Config interface for testing
+// MockConfig is a mock implementation of the Config interface for testing, it does not
+// implement config.SentryConfigProvider like configs written before Sentry settings existed
 type MockConfig struct {
 	ServiceName       string
 	Environment       string
@@ -16,6 +18,9 @@ type MockConfig struct {
 	CollectorEndpoint string
 	Insecure          bool
 	DebugMode         bool
+	TracesSampleRate  float64
+	Release           string
+	EnableLogs        bool
 }
 
 func (c *MockConfig) GetServiceName() string               { return c.ServiceName }
@@ -25,6 +30,9 @@ func (c *MockConfig) GetExporterType() config.ExporterType { return c.ExporterTy
 func (c *MockConfig) GetCollectorEndpoint() string         { return c.CollectorEndpoint }
 func (c *MockConfig) IsInsecure() bool                     { return c.Insecure }
 func (c *MockConfig) IsDebugMode() bool                    { return c.DebugMode }
+func (c *MockConfig) GetTracesSampleRate() float64         { return c.TracesSampleRate }
+func (c *MockConfig) GetRelease() string                   { return c.Release }
+func (c *MockConfig) IsLogsEnabled() bool                  { return c.EnableLogs }
 
 func TestInitTracer_Sentry(t *testing.T) {
 	cfg := &MockConfig{
@@ -105,3 +113,20 @@ func TestInitTracer_UnknownExporter(t *testing.T) {
 	assert.Error(t, err, "InitTracer should return an error for unknown exporter")
 	assert.Nil(t, tp, "TracerProvider should not be initialized for unknown exporter")
 }
+
+func TestNewTracerProvider_LeavesGlobalsUntouched(t *testing.T) {
+	cfg := &MockConfig{
+		ServiceName:  "test-service",
+		ExporterType: config.ExporterTypeStdout,
+	}
+
+	before := otel.GetTracerProvider()
+	tp, err := NewTracerProvider(cfg)
+	assert.NoError(t, err, "NewTracerProvider should not return an error for Stdout")
+	assert.NotNil(t, tp, "TracerProvider should be initialized for Stdout")
+	assert.Same(t, before, otel.GetTracerProvider(), "global tracer provider should not change")
+
+	tp, err = NewTracerProvider(cfg, WithGlobals())
+	assert.NoError(t, err)
+	assert.Same(t, tp, otel.GetTracerProvider(), "WithGlobals should install the tracer provider")
+}